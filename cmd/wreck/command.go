@@ -0,0 +1,163 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// command represents one of wreck's subcommands.
+type command struct {
+	name    string // name used to run the command
+	args    string // usage of the command's flags and arguments
+	summary string // short description of the command
+
+	// run runs the command in the given environment, with the arguments
+	// following the command's name.
+	run func(e *env, args []string) error
+}
+
+// env represents the environment a command runs in. Commands use it's
+// streams instead of the standard streams of the os package, so that they
+// can be run with any input and output.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// osEnv returns the environment of the wreck process.
+func osEnv() *env {
+	return &env{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+}
+
+// isTerminal checks if the environment's input and output are both
+// terminals, so it can be used interactively.
+func (e *env) isTerminal() bool {
+	in, inFile := e.stdin.(*os.File)
+	out, outFile := e.stdout.(*os.File)
+	return inFile && outFile && isTerminal(in) && isTerminal(out)
+}
+
+// commands is the registry of wreck's subcommands, indexed by their names.
+// Each command registers itself from an init function in it's own file.
+var commands = make(map[string]*command)
+
+// register adds the given command to the registry.
+func register(c *command) {
+	commands[c.name] = c
+}
+
+// commandNames returns the names of the registered commands in order.
+func commandNames() []string {
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// errUsage is returned by commands which have been given invalid flags or
+// arguments, after their usage has been reported.
+var errUsage = errors.New("invalid usage")
+
+// flagSet creates a new flag set for the command with the given name. It's
+// errors and usage are written to the environment's error stream.
+func (e *env) flagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	flags.Usage = func() {
+		e.usage(name)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+// parse parses the given arguments with the given flag set, and checks
+// that the number of remaining arguments is between min and max. If max is
+// negative, any number of arguments above min is allowed. It returns
+// errUsage after reporting the usage of the command if the arguments are
+// invalid.
+func (e *env) parse(flags *flag.FlagSet, args []string, min, max int) error {
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return err
+		}
+
+		return errUsage
+	}
+
+	if flags.NArg() < min || (max >= 0 && flags.NArg() > max) {
+		flags.Usage()
+		return errUsage
+	}
+
+	return nil
+}
+
+// usage reports the usage of the command with the given name.
+func (e *env) usage(name string) {
+	fmt.Fprintf(e.stderr, "usage: wreck %s %s\n", name, commands[name].args)
+}
+
+func init() {
+	register(&command{
+		name:    "help",
+		args:    "[command]",
+		summary: "show help regarding wreck's commands",
+		run:     help,
+	})
+}
+
+// help implements the help subcommand, which lists wreck's commands, or
+// shows the usage and flags of a single command.
+func help(e *env, args []string) error {
+	flags := e.flagSet("help")
+	if err := e.parse(flags, args, 0, 1); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 1 {
+		c, found := commands[flags.Arg(0)]
+		if !found {
+			return fmt.Errorf("unknown command %#v", flags.Arg(0))
+		}
+
+		fmt.Fprintf(e.stdout, "usage: wreck %s %s\n\n%s\n", c.name, c.args, c.summary)
+		fmt.Fprintf(e.stdout, "\nRun 'wreck %s -h' for the command's flags.\n", c.name)
+		return nil
+	}
+
+	fmt.Fprintln(e.stdout, "usage: wreck [-c commands | -f file] [position]")
+	fmt.Fprintln(e.stdout, "       wreck <command> [flags]")
+	fmt.Fprintln(e.stdout, "\nCommands:")
+	for _, name := range commandNames() {
+		fmt.Fprintf(e.stdout, "  %-9s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(e.stdout, "\nRun 'wreck help <command>' for the usage of a command.")
+	return nil
+}
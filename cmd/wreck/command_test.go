@@ -0,0 +1,442 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+// runTest runs wreck with the given arguments and input in an environment
+// backed by buffers, and returns it's exit status and output.
+func runTest(t *testing.T, input string, args ...string) (status int, stdout, stderr string) {
+	t.Helper()
+
+	var out, errOut bytes.Buffer
+	e := &env{
+		stdin:  strings.NewReader(input),
+		stdout: &out,
+		stderr: &errOut,
+	}
+
+	status = run(e, args)
+	return status, out.String(), errOut.String()
+}
+
+func TestReplCommands(t *testing.T) {
+	status, stdout, stderr := runTest(t, "", "repl", "-c", "load x........; play 5; hint; level")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	for _, want := range []string{"[turn of player o]", "[turn of player x]", "try playing", "level 10"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %#v:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestReplScript(t *testing.T) {
+	// commands read from a non-terminal input are run as a script
+	status, stdout, stderr := runTest(t, "play 5 # centre\nthreats\n")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	if strings.Contains(stdout, "wreck ::") {
+		t.Error("script printed a prompt")
+	}
+
+	if !strings.Contains(stdout, "Player x:") {
+		t.Errorf("threats missing from output:\n%s", stdout)
+	}
+}
+
+func TestReplScriptFails(t *testing.T) {
+	status, stdout, stderr := runTest(t, "", "-c", "play 5; play 5; eval")
+	if status != 1 {
+		t.Errorf("status %d, want 1", status)
+	}
+
+	if !strings.Contains(stderr, "invalid move 5") || !strings.Contains(stderr, "script failed") {
+		t.Errorf("unexpected errors:\n%s", stderr)
+	}
+
+	// the commands after the failed one are still run
+	if !strings.Contains(stdout, "Line     : Evaluation") {
+		t.Errorf("eval missing from output:\n%s", stdout)
+	}
+}
+
+func TestEval(t *testing.T) {
+	status, stdout, stderr := runTest(t, "", "eval", "x........")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	if !strings.Contains(stdout, "[turn of player o]") || !strings.Contains(stdout, "Move 5 : ±00") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+
+	if status, _, _ := runTest(t, "", "eval", "xxx"); status != 1 {
+		t.Errorf("invalid position: status %d, want 1", status)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	status, stdout, stderr := runTest(t, "9 9\nb2\nresign\n", "play", "-as", "o", "x........")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	for _, want := range []string{
+		"your move (2 3 4 5 6 7 8 9)",
+		`"9 9" is not a valid move`,
+		"level 10 plays",
+		"x wins", // after o resigns
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %#v:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestPlayEnds(t *testing.T) {
+	// x has a fork after 5, so it wins against perfect play
+	status, stdout, stderr := runTest(t, "5\n6\n", "play", "xo.x.....")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	if !strings.HasSuffix(stdout, "x x x\no . o\n\nx wins\n") {
+		t.Errorf("unexpected end of output:\n%s", stdout)
+	}
+}
+
+func TestTrainNotation(t *testing.T) {
+	puzzles := filepath.Join(t.TempDir(), "puzzles.txt")
+	if err := os.WriteFile(puzzles, []byte("xoxo..... fork 5,9\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, stdout, stderr := runTest(t, "b2\n6\n3,3\nz9\nskip\n",
+		"train", "-file", puzzles, "-notation", "algebraic")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	for _, want := range []string{
+		"correct! b2 leads to",
+		"wrong: c2 leads to",
+		`"z9" is not a valid move`,
+		"best: b2 c1",
+		"final score: 2/3",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %#v:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	tests := [][]string{
+		{"bogus"},
+		{"eval", "-bogus"},
+		{"eval", "x........", "extra"},
+		{"play", "-level"},
+		{"repl", "-c", "eval", "-f", "script.wrk"},
+		{"nn"},
+	}
+
+	for _, args := range tests {
+		status, _, stderr := runTest(t, "", args...)
+		if status != 2 {
+			t.Errorf("%v: status %d, want 2", args, status)
+		}
+
+		if stderr == "" {
+			t.Errorf("%v: usage not reported", args)
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	status, stdout, _ := runTest(t, "", "help")
+	if status != 0 {
+		t.Fatalf("status %d", status)
+	}
+
+	for _, name := range commandNames() {
+		if !strings.Contains(stdout, "\n  "+name+" ") {
+			t.Errorf("command %s missing from help:\n%s", name, stdout)
+		}
+	}
+
+	if status, _, _ := runTest(t, "", "eval", "-h"); status != 0 {
+		t.Errorf("eval -h: status %d, want 0", status)
+	}
+}
+
+func TestServe(t *testing.T) {
+	s := server{
+		table: tablebase.Generate(),
+		log:   log.New(io.Discard, "", 0),
+	}
+
+	server := httptest.NewServer(s.handler())
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/eval?position=xo.x.....")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", response.StatusCode)
+	}
+
+	var eval evalResponse
+	if err := json.NewDecoder(response.Body).Decode(&eval); err != nil {
+		t.Fatal(err)
+	}
+
+	if eval.Position != "xo.x....." || eval.Turn != "o" || eval.Eval != "+W3" {
+		t.Errorf("unexpected response %+v", eval)
+	}
+
+	if len(eval.BestMoves) != 1 || eval.BestMoves[0] != 7 || len(eval.Moves) != 6 {
+		t.Errorf("unexpected moves %+v", eval)
+	}
+
+	tests := []struct {
+		method, query string
+		status        int
+	}{
+		{http.MethodGet, "position=bogus", http.StatusBadRequest},
+		{http.MethodGet, "position=xxx......", http.StatusBadRequest},
+		{http.MethodPost, "position=x........", http.StatusMethodNotAllowed},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest(test.method, "/eval?"+test.query, nil)
+		recorder := httptest.NewRecorder()
+		s.handler().ServeHTTP(recorder, request)
+
+		if recorder.Code != test.status {
+			t.Errorf("%s %s: status %d, want %d", test.method, test.query, recorder.Code, test.status)
+		}
+	}
+}
+
+func TestPuzzles(t *testing.T) {
+	status, stdout, stderr := runTest(t, "", "puzzles", "-kind", "fork")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	puzzles, err := tablebase.ReadPuzzles(strings.NewReader(stdout))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(puzzles) == 0 {
+		t.Fatal("no fork puzzles exported")
+	}
+
+	for _, puzzle := range puzzles {
+		if puzzle.Kind != tablebase.ForkSetup {
+			t.Errorf("puzzle %s is not a fork", puzzle)
+		}
+	}
+
+	output := filepath.Join(t.TempDir(), "puzzles.txt")
+	if status, stdout, _ := runTest(t, "", "puzzles", "-o", output); status != 0 || stdout != "" {
+		t.Errorf("puzzles -o: status %d, output %#v", status, stdout)
+	}
+
+	if data, err := os.ReadFile(output); err != nil || len(data) == 0 {
+		t.Errorf("puzzles -o didn't write the puzzle set: %v", err)
+	}
+
+	if status, _, _ := runTest(t, "", "puzzles", "-kind", "bogus"); status != 1 {
+		t.Errorf("unknown kind: status %d, want 1", status)
+	}
+}
+
+func TestGen(t *testing.T) {
+	_, first, _ := runTest(t, "", "gen", "-count", "5", "-ply", "3", "-seed", "0")
+	status, second, stderr := runTest(t, "", "gen", "-count", "5", "-ply", "3", "-seed", "0")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	if first != second {
+		t.Errorf("seed 0 isn't reproducible:\n%s\n%s", first, second)
+	}
+
+	lines := strings.Fields(first)
+	if len(lines) != 5 {
+		t.Fatalf("got %d positions, want 5", len(lines))
+	}
+
+	for _, line := range lines {
+		if b, err := board.New(line); err != nil || b.MoveNumber() != 3 {
+			t.Errorf("position %s doesn't have move number 3", line)
+		}
+	}
+
+	status, stdout, stderr := runTest(t, "", "gen", "-games", "-count", "2", "-outcome", "draw", "-epsilon", "0")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if fields := strings.Split(line, "\t"); len(fields) != 3 || fields[2] != "draw" {
+			t.Errorf("unexpected game record %#v", line)
+		}
+	}
+
+	for _, args := range [][]string{{"-count", "-1"}, {"-ply", "10"}, {"-outcome", "bogus"}, {"-eval", "+W9", "-ply", "8"}} {
+		if status, _, _ := runTest(t, "", append([]string{"gen"}, args...)...); status != 1 {
+			t.Errorf("gen %v: status %d, want 1", args, status)
+		}
+	}
+}
+
+func TestDataset(t *testing.T) {
+	status, stdout, stderr := runTest(t, "", "dataset")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if !strings.HasPrefix(lines[0], "position,x1,") || len(lines) != 5479 {
+		t.Errorf("unexpected csv dataset with %d lines, header %#v", len(lines), lines[0])
+	}
+
+	_, symmetric, _ := runTest(t, "", "dataset", "-format", "jsonl", "-symmetry")
+	if n := strings.Count(symmetric, "\n"); n == 0 || n >= 5478 {
+		t.Errorf("-symmetry exported %d positions", n)
+	}
+
+	output := filepath.Join(t.TempDir(), "dataset.npy")
+	if status, _, stderr := runTest(t, "", "dataset", "-format", "npy", "-o", output); status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	if data, err := os.ReadFile(output); err != nil || !strings.HasPrefix(string(data), "\x93NUMPY") {
+		t.Errorf("dataset -o didn't write an npy file: %v", err)
+	}
+
+	if status, _, _ := runTest(t, "", "dataset", "-format", "bogus"); status != 1 {
+		t.Errorf("unknown format: status %d, want 1", status)
+	}
+}
+
+func TestGolden(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "golden.epd")
+	if status, _, stderr := runTest(t, "", "golden", "-o", file); status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	status, stdout, stderr := runTest(t, "", "golden", "-check", file)
+	if status != 0 || !strings.Contains(stdout, "all 5478 entries match") {
+		t.Fatalf("status %d, output %#v, stderr: %s", status, stdout, stderr)
+	}
+
+	// change the evaluation of the starting position
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data = []byte(strings.Replace(string(data), "......... ±00", "......... +W9", 1))
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	status, stdout, _ = runTest(t, "", "golden", "-check", file)
+	if status != 1 || !strings.Contains(stdout, "evaluation is ±00, expected +W9") {
+		t.Errorf("changed golden file: status %d, output %#v", status, stdout)
+	}
+
+	if status, _, _ := runTest(t, "", "golden", "-o", file, "-check", file); status != 2 {
+		t.Errorf("golden -o -check: status %d, want 2", status)
+	}
+}
+
+func TestTrain(t *testing.T) {
+	_, first, _ := runTest(t, "", "train", "-level", "easy", "-seed", "7")
+	status, second, stderr := runTest(t, "", "train", "-level", "easy", "-seed", "7")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	if first != second || !strings.Contains(first, "final score: 0/0") {
+		t.Errorf("unexpected training sessions:\n%s\n%s", first, second)
+	}
+
+	status, stdout, _ := runTest(t, "skip\nquit\n", "train", "-level", "medium")
+	if status != 0 || !strings.Contains(stdout, "best: ") {
+		t.Errorf("status %d, output:\n%s", status, stdout)
+	}
+
+	for _, args := range [][]string{{"-level", "bogus"}, {"-file", "missing.txt"}} {
+		if status, _, _ := runTest(t, "", append([]string{"train"}, args...)...); status != 1 {
+			t.Errorf("train %v: status %d, want 1", args, status)
+		}
+	}
+}
+
+func TestLevels(t *testing.T) {
+	status, stdout, stderr := runTest(t, "", "levels", "-games", "2")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 11 {
+		t.Fatalf("got %d lines, want a header and 10 levels:\n%s", len(lines), stdout)
+	}
+
+	// the strongest level never errs or loses
+	if !strings.HasPrefix(lines[10], "   10 :    0/4520 ( 0%) : +0 =2 -0") {
+		t.Errorf("unexpected result of level 10: %#v", lines[10])
+	}
+}
+
+func TestTune(t *testing.T) {
+	status, stdout, stderr := runTest(t, "", "tune", "-lambda", "0.5")
+	if status != 0 {
+		t.Fatalf("status %d, stderr: %s", status, stderr)
+	}
+
+	for _, want := range []string{"Weights:", "tempo", "Default : rmse"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %#v:\n%s", want, stdout)
+		}
+	}
+}
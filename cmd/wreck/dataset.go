@@ -0,0 +1,105 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/dataset"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "dataset",
+		args:    "[-format format] [-symmetry] [-o file]",
+		summary: "export the tablebase as training data for machine learning",
+		run:     datasetCmd,
+	})
+}
+
+// datasetCmd implements the dataset subcommand, which exports every position
+// in the tablebase as training data for machine learning models.
+func datasetCmd(e *env, args []string) error {
+	flags := e.flagSet("dataset")
+	formatName := flags.String("format", "csv", "format of the dataset (csv, npy, jsonl)")
+	symmetry := flags.Bool("symmetry", false, "only export one position from each set of symmetric positions")
+	output := flags.String("o", "", "write the dataset to this file instead of stdout")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	format, found := dataset.ParseFormat(*formatName)
+	if !found {
+		return fmt.Errorf("unknown dataset format %#v", *formatName)
+	}
+
+	records := tableRecords(tablebase.Generate(), *symmetry)
+
+	if *output == "" {
+		return writeDataset(e.stdout, format, records)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := writeDataset(file, format, records); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// writeDataset writes the records to w in the given format.
+func writeDataset(w io.Writer, format dataset.Format, records []dataset.Record) error {
+	buffer := bufio.NewWriter(w)
+	if err := dataset.Write(buffer, format, records); err != nil {
+		return err
+	}
+
+	return buffer.Flush()
+}
+
+// tableRecords converts every position in the given tablebase into a
+// dataset record. If symmetry is true, only one position from each set of
+// symmetric positions is converted.
+func tableRecords(table *tablebase.Table, symmetry bool) []dataset.Record {
+	var records []dataset.Record
+	seen := make(map[board.Board]bool)
+
+	for _, data := range table.Positions() {
+		position := data.Position()
+		if symmetry {
+			// use the canonical position to represent it's symmetries
+			position = position.Canonical()
+			if seen[position] {
+				continue
+			}
+
+			seen[position] = true
+			data, _ = table.Search(position)
+		}
+
+		records = append(records, dataset.NewRecord(position, data.AbsEval(), data.BestMoves()))
+	}
+
+	return records
+}
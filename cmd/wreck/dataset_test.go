@@ -0,0 +1,40 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func TestTableRecords(t *testing.T) {
+	table := tablebase.Generate()
+
+	if n := len(tableRecords(table, false)); n != 5478 {
+		t.Errorf("got %d records, want every one of the 5478 positions", n)
+	}
+
+	// 765 positions are distinct up to rotations and reflections
+	records := tableRecords(table, true)
+	if len(records) != 765 {
+		t.Errorf("got %d records with -symmetry, want 765", len(records))
+	}
+
+	for _, r := range records {
+		if r.Position != r.Position.Canonical() {
+			t.Errorf("%s is not canonical", r.Position.PositionString())
+		}
+	}
+}
@@ -0,0 +1,371 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// maxHistory is the maximum number of lines kept in the history.
+const maxHistory = 1000
+
+// historyFile returns the path of the file where the repl's history is
+// persisted, or an empty string if the user's home directory is unknown.
+func historyFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".wreck_history")
+}
+
+// editor is a line editor for terminals, which supports moving the cursor
+// inside the line, recalling previous lines from the history, and tab
+// completion. If the terminal can't be put into raw mode, the lines are
+// read as they are without any editing.
+type editor struct {
+	in  *bufio.Reader
+	out io.Writer
+	fd  uintptr // file descriptor of the terminal
+
+	history []string // previously entered lines, oldest first
+	file    string   // file where the history is persisted
+
+	// complete returns the possible completions of the last word of the
+	// given text, which is the part of the line before the cursor.
+	complete func(text string) []string
+}
+
+// newEditor creates a new line editor on the given terminal, which writes
+// to out. The history is loaded from and persisted to the given file, if
+// it is not empty.
+func newEditor(terminal *os.File, out io.Writer, file string) *editor {
+	e := &editor{
+		in:   bufio.NewReader(terminal),
+		out:  out,
+		fd:   terminal.Fd(),
+		file: file,
+	}
+
+	e.loadHistory()
+	return e
+}
+
+// loadHistory loads the history from the history file. A missing or
+// unreadable history file is treated as an empty history. If the file has
+// more than maxHistory lines, it is rewritten with only the last ones.
+func (e *editor) loadHistory() {
+	if e.file == "" {
+		return
+	}
+
+	data, err := os.ReadFile(e.file)
+	if err != nil {
+		return
+	}
+
+	var lines int
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			e.history = append(e.history, line)
+			lines++
+		}
+	}
+
+	if lines > maxHistory {
+		e.history = e.history[lines-maxHistory:]
+		e.saveHistory()
+	}
+}
+
+// addHistory adds the given line to the history and saves it to the
+// history file, unless it is empty or repeats the previous line.
+func (e *editor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	if len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return
+	}
+
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[1:]
+	}
+
+	e.saveHistory()
+}
+
+// saveHistory rewrites the history file with the history, so that it never
+// grows beyond maxHistory lines. Errors while writing to the history file
+// are ignored, since losing the history is not worth interrupting the user
+// for.
+func (e *editor) saveHistory() {
+	if e.file == "" {
+		return
+	}
+
+	var s strings.Builder
+	for _, line := range e.history {
+		s.WriteString(line)
+		s.WriteByte('\n')
+	}
+
+	os.WriteFile(e.file, []byte(s.String()), 0o600)
+}
+
+// readLine prints the given prompt and reads a line from the terminal,
+// which is returned without the trailing newline. It returns io.EOF if
+// ctrl-d is pressed on an empty line.
+func (e *editor) readLine(prompt string) (string, error) {
+	fmt.Fprint(e.out, prompt)
+
+	restore, err := makeRaw(e.fd)
+	if err != nil {
+		// read the line as it is if raw mode is not available
+		line, err := e.in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+
+		return strings.TrimRight(line, "\r\n"), err
+	}
+	defer restore()
+
+	l := lineState{prompt: prompt, index: len(e.history)}
+	for {
+		key, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch key {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			line := string(l.buffer)
+			e.addHistory(line)
+			return line, nil
+
+		case 3: // ctrl-c, discard the line
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", nil
+
+		case 4: // ctrl-d, end of input on an empty line
+			if len(l.buffer) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+
+			l.delete()
+
+		case 127, 8: // backspace, ctrl-h
+			if l.cursor > 0 {
+				l.cursor--
+				l.delete()
+			}
+
+		case 1: // ctrl-a
+			l.cursor = 0
+		case 5: // ctrl-e
+			l.cursor = len(l.buffer)
+		case 2: // ctrl-b
+			l.left()
+		case 6: // ctrl-f
+			l.right()
+		case 16: // ctrl-p
+			l.recall(e.history, -1)
+		case 14: // ctrl-n
+			l.recall(e.history, 1)
+
+		case 11: // ctrl-k, delete till the end of the line
+			l.buffer = l.buffer[:l.cursor]
+		case 21: // ctrl-u, delete till the start of the line
+			l.buffer = l.buffer[l.cursor:]
+			l.cursor = 0
+
+		case '\t':
+			e.completeWord(&l)
+
+		case 27: // escape sequence, arrow keys and friends
+			switch e.escapeSequence() {
+			case "[A", "OA":
+				l.recall(e.history, -1)
+			case "[B", "OB":
+				l.recall(e.history, 1)
+			case "[C", "OC":
+				l.right()
+			case "[D", "OD":
+				l.left()
+			case "[H", "OH", "[1~", "[7~":
+				l.cursor = 0
+			case "[F", "OF", "[4~", "[8~":
+				l.cursor = len(l.buffer)
+			case "[3~":
+				l.delete()
+			}
+
+		default:
+			if unicode.IsPrint(key) {
+				l.insert([]rune{key})
+			}
+		}
+
+		l.draw(e.out)
+	}
+}
+
+// escapeSequence reads the rest of an escape sequence after the escape
+// character, and returns it without the escape character.
+func (e *editor) escapeSequence() string {
+	kind, err := e.in.ReadByte()
+	if err != nil || (kind != '[' && kind != 'O') {
+		return ""
+	}
+
+	sequence := []byte{kind}
+	for {
+		b, err := e.in.ReadByte()
+		if err != nil {
+			return ""
+		}
+
+		// sequences end with a byte in the range @ to ~
+		sequence = append(sequence, b)
+		if b >= '@' && b <= '~' {
+			return string(sequence)
+		}
+	}
+}
+
+// completeWord completes the word before the cursor. If there is a single
+// completion, the word is replaced with it. Otherwise, the word is extended
+// to the longest common prefix of the completions, and if that isn't
+// possible, the completions are listed below the line.
+func (e *editor) completeWord(l *lineState) {
+	if e.complete == nil {
+		return
+	}
+
+	text := string(l.buffer[:l.cursor])
+	word := lastWord(text)
+
+	completions := e.complete(text)
+	switch len(completions) {
+	case 0:
+		return
+	case 1:
+		l.insert([]rune(strings.TrimPrefix(completions[0], word) + " "))
+		return
+	}
+
+	prefix := completions[0]
+	for _, completion := range completions[1:] {
+		for !strings.HasPrefix(completion, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	if len(prefix) > len(word) {
+		l.insert([]rune(strings.TrimPrefix(prefix, word)))
+		return
+	}
+
+	fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(completions, "  "))
+}
+
+// lastWord returns the word at the end of the given text, which is the
+// one replaced by completions. Words are separated by whitespace, and by
+// the semicolons which separate commands.
+func lastWord(text string) string {
+	return text[strings.LastIndexAny(text, " \t;")+1:]
+}
+
+// lineState stores the state of the line being edited.
+type lineState struct {
+	prompt string
+	buffer []rune
+	cursor int // index of the rune under the cursor
+
+	index int    // index of the history entry being shown
+	saved []rune // line being edited before recalling the history
+}
+
+// insert inserts the given runes at the cursor.
+func (l *lineState) insert(runes []rune) {
+	buffer := append([]rune{}, l.buffer[:l.cursor]...)
+	buffer = append(buffer, runes...)
+	l.buffer = append(buffer, l.buffer[l.cursor:]...)
+	l.cursor += len(runes)
+}
+
+// delete deletes the rune under the cursor.
+func (l *lineState) delete() {
+	if l.cursor < len(l.buffer) {
+		l.buffer = append(l.buffer[:l.cursor], l.buffer[l.cursor+1:]...)
+	}
+}
+
+// left moves the cursor one rune to the left.
+func (l *lineState) left() {
+	if l.cursor > 0 {
+		l.cursor--
+	}
+}
+
+// right moves the cursor one rune to the right.
+func (l *lineState) right() {
+	if l.cursor < len(l.buffer) {
+		l.cursor++
+	}
+}
+
+// recall replaces the line with the history entry at the given offset from
+// the one being shown. Moving past the newest entry restores the line which
+// was being edited.
+func (l *lineState) recall(history []string, offset int) {
+	index := l.index + offset
+	if index < 0 || index > len(history) {
+		return
+	}
+
+	if l.index == len(history) {
+		l.saved = l.buffer
+	}
+
+	l.index = index
+	if index == len(history) {
+		l.buffer = l.saved
+	} else {
+		l.buffer = []rune(history[index])
+	}
+
+	l.cursor = len(l.buffer)
+}
+
+// draw redraws the line on the terminal, and moves the terminal's cursor
+// to the line's cursor.
+func (l *lineState) draw(out io.Writer) {
+	fmt.Fprintf(out, "\r%s%s\x1b[K", l.prompt, string(l.buffer))
+	if back := len(l.buffer) - l.cursor; back > 0 {
+		fmt.Fprintf(out, "\x1b[%dD", back)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+)
+
+func TestLineStateEditing(t *testing.T) {
+	var l lineState
+	l.insert([]rune("play"))
+	l.left()
+	l.left()
+	l.insert([]rune("X"))
+	if got := string(l.buffer); got != "plXay" || l.cursor != 3 {
+		t.Fatalf("after insert: %#v, cursor %d", got, l.cursor)
+	}
+
+	l.delete()
+	if got := string(l.buffer); got != "plXy" {
+		t.Fatalf("after delete: %#v", got)
+	}
+
+	// the cursor stays inside the line
+	for i := 0; i < 10; i++ {
+		l.right()
+	}
+
+	l.delete()
+	if l.cursor != 4 || string(l.buffer) != "plXy" {
+		t.Fatalf("at end: %#v, cursor %d", string(l.buffer), l.cursor)
+	}
+
+	for i := 0; i < 10; i++ {
+		l.left()
+	}
+
+	if l.cursor != 0 {
+		t.Fatalf("at start: cursor %d", l.cursor)
+	}
+}
+
+func TestLineStateRecall(t *testing.T) {
+	history := []string{"load x........", "play 5", "eval"}
+
+	l := lineState{index: len(history)}
+	l.insert([]rune("hi"))
+
+	l.recall(history, -1)
+	if got := string(l.buffer); got != "eval" || l.cursor != 4 {
+		t.Fatalf("up: %#v, cursor %d", got, l.cursor)
+	}
+
+	l.recall(history, -1)
+	l.recall(history, -1)
+	l.recall(history, -1) // past the oldest entry
+	if got := string(l.buffer); got != "load x........" {
+		t.Fatalf("up past the oldest entry: %#v", got)
+	}
+
+	// editing a recalled line doesn't change the history
+	l.insert([]rune(" x"))
+	if history[0] != "load x........" {
+		t.Fatalf("history modified: %#v", history[0])
+	}
+
+	for i := 0; i < 3; i++ {
+		l.recall(history, 1)
+	}
+
+	if got := string(l.buffer); got != "hi" || l.cursor != 2 {
+		t.Fatalf("down to the edited line: %#v, cursor %d", got, l.cursor)
+	}
+
+	l.recall(history, 1) // past the edited line
+	if got := string(l.buffer); got != "hi" {
+		t.Fatalf("down past the edited line: %#v", got)
+	}
+}
+
+func TestCompleteWord(t *testing.T) {
+	var out bytes.Buffer
+	s := newSession(board.Board{}, &out, &out, false)
+	e := &editor{out: &out, complete: s.complete}
+
+	tests := []struct {
+		line, want string
+	}{
+		{"pl", "play "},
+		{"h", "h"}, // help and hint
+		{"th", "threats "},
+		{"load x........; ev", "load x........; eval "},
+		{"play 5;pl", "play 5;play "},
+		{"play 5;ev", "play 5;eval "},
+		{"load x........;\tth", "load x........;\tthreats "},
+		{"play\t5", "play\t5 "},
+		{"play ", "play "}, // every move is legal
+		{"play 5", "play 5 "},
+		{"eval 5", "eval 5"},
+		{"zz", "zz"},
+	}
+
+	for _, test := range tests {
+		l := lineState{}
+		l.insert([]rune(test.line))
+		e.completeWord(&l)
+
+		if got := string(l.buffer); got != test.want {
+			t.Errorf("completing %#v = %#v, want %#v", test.line, got, test.want)
+		}
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history")
+
+	var lines strings.Builder
+	for i := 0; i < maxHistory+10; i++ {
+		fmt.Fprintf(&lines, "eval %d\n", i)
+	}
+
+	if err := os.WriteFile(file, []byte(lines.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &editor{file: file}
+	e.loadHistory()
+	if len(e.history) != maxHistory || e.history[0] != "eval 10" {
+		t.Fatalf("loaded %d lines starting with %#v", len(e.history), e.history[0])
+	}
+
+	e.addHistory("play 5")
+	e.addHistory("play 5") // repeated lines are skipped
+	e.addHistory("  ")     // and so are empty ones
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	saved := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(saved) != maxHistory || saved[0] != "eval 11" || saved[len(saved)-1] != "play 5" {
+		t.Errorf("saved %d lines from %#v to %#v", len(saved), saved[0], saved[len(saved)-1])
+	}
+}
+
+func TestReadLineWithoutTerminal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	io.WriteString(w, "play 5\nlast")
+	w.Close()
+
+	var out bytes.Buffer
+	e := newEditor(r, &out, "")
+
+	for _, want := range []string{"play 5", "last"} {
+		if line, err := e.readLine("> "); err != nil || line != want {
+			t.Errorf("readLine() = %#v, %v, want %#v", line, err, want)
+		}
+	}
+
+	if _, err := e.readLine("> "); err != io.EOF {
+		t.Errorf("readLine() at the end = %v, want EOF", err)
+	}
+}
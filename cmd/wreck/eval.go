@@ -0,0 +1,51 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "eval",
+		args:    "[position]",
+		summary: "evaluate a position and show it's moves",
+		run:     evalPosition,
+	})
+}
+
+// evalPosition implements the eval subcommand, which prints the data of a
+// position from the tablebase, like the eval command of the repl.
+func evalPosition(e *env, args []string) error {
+	flags := e.flagSet("eval")
+	if err := e.parse(flags, args, 0, 1); err != nil {
+		return err
+	}
+
+	b, err := parsePosition(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	data, found := tablebase.Generate().Search(b)
+	if !found {
+		return fmt.Errorf("position %s is not legal", b.PositionString())
+	}
+
+	fmt.Fprint(e.stdout, data.String())
+	return nil
+}
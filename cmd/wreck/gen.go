@@ -0,0 +1,146 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"time"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+	"laptudirm.com/x/wreck/pkg/sample"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "gen",
+		args:    "[-count n] [-ply k] [-eval eval] [-outcome result] [-games] [-epsilon e] [-seed seed]",
+		summary: "generate random positions or games",
+		run:     gen,
+	})
+}
+
+// gen implements the gen subcommand, which generates random legal positions
+// or random games and writes them to the standard output, one per line.
+func gen(e *env, args []string) error {
+	flags := e.flagSet("gen")
+	count := flags.Int("count", 10, "number of positions or games to generate")
+	ply := flags.Int("ply", -1, "only generate positions with this move number")
+	eval := flags.String("eval", "", "only generate positions with this evaluation, like +W3 or ±00")
+	outcome := flags.String("outcome", "", "only generate positions with this result (x, o, draw)")
+	games := flags.Bool("games", false, "generate games instead of positions")
+	epsilon := flags.Float64("epsilon", 1, "probability of a random move in games, else a best move is played")
+	seed := flags.Int64("seed", 0, "seed for the random generator (default: current time)")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *ply > 9 {
+		return fmt.Errorf("invalid move number %d", *ply)
+	}
+
+	if *count < 0 {
+		return fmt.Errorf("invalid count %d", *count)
+	}
+
+	// seed with the current time unless a seed was given, so that every
+	// seed, including 0, can be reproduced
+	seeded := false
+	flags.Visit(func(f *flag.Flag) {
+		seeded = seeded || f.Name == "seed"
+	})
+
+	if !seeded {
+		*seed = time.Now().UnixNano()
+	}
+
+	table := tablebase.Generate()
+
+	// combine the filters given in the flags
+	var filters []sample.Filter
+	if *eval != "" {
+		abs, err := evaluation.ParseAbs(*eval)
+		if err != nil {
+			return err
+		}
+
+		filters = append(filters, sample.WithEval(table, abs))
+	}
+
+	switch *outcome {
+	case "":
+	case "x":
+		filters = append(filters, sample.WithOutcome(table, board.PlayerXWon))
+	case "o":
+		filters = append(filters, sample.WithOutcome(table, board.PlayerOWon))
+	case "draw":
+		filters = append(filters, sample.WithOutcome(table, board.GameDrawn))
+	default:
+		return fmt.Errorf("unknown result %#v", *outcome)
+	}
+
+	filter := func(b board.Board) bool {
+		for _, f := range filters {
+			if !f(b) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	generator := sample.New(*seed)
+	out := bufio.NewWriter(e.stdout)
+
+	if !*games {
+		positions, err := generator.Positions(*count, *ply, filter)
+		if err != nil {
+			return err
+		}
+
+		for _, position := range positions {
+			fmt.Fprintln(out, position.PositionString())
+		}
+
+		return out.Flush()
+	}
+
+	// games start from the starting position, unless constraints on the
+	// starting position are provided
+	starts := make([]board.Board, *count)
+	if *ply >= 0 || len(filters) > 0 {
+		var err error
+		starts, err = generator.Positions(*count, *ply, filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, start := range starts {
+		moves := generator.Game(start, table, *epsilon)
+
+		end := start
+		for _, move := range moves {
+			end.Play(move)
+		}
+
+		// game records are of the form <start> <moves> <result>
+		fmt.Fprintf(out, "%s\t%s\t%s\n", start.PositionString(), board.Numeric.FormatMoves(moves), end.State())
+	}
+
+	return out.Flush()
+}
@@ -0,0 +1,105 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "golden",
+		args:    "[-o file | -check file]",
+		summary: "write the tablebase to a golden file, or check it against one",
+		run:     golden,
+	})
+}
+
+// golden implements the golden subcommand, which writes every tablebase
+// entry as an annotation to a golden file, or checks every entry against
+// a previously written golden file to catch regressions.
+func golden(e *env, args []string) error {
+	flags := e.flagSet("golden")
+	check := flags.String("check", "", "check the tablebase against this golden file")
+	output := flags.String("o", "", "write the golden file to this file instead of stdout")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *check != "" && *output != "" {
+		e.usage("golden")
+		return errUsage
+	}
+
+	table := tablebase.Generate()
+
+	if *check != "" {
+		return checkGolden(e.stdout, table, *check)
+	}
+
+	if *output == "" {
+		return writeGolden(e.stdout, table)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := writeGolden(file, table); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// writeGolden writes every entry of the tablebase as an annotation to w,
+// after a comment describing the format.
+func writeGolden(w io.Writer, table *tablebase.Table) error {
+	fmt.Fprintln(w, "# wreck tablebase golden file: position eval bestmoves")
+	return tablebase.WriteAnnotations(w, table.Annotations())
+}
+
+// checkGolden checks every entry of the tablebase against the golden file
+// with the given name, printing each mismatch to w. It returns an error if
+// any entry doesn't match, or is missing from the file.
+func checkGolden(w io.Writer, table *tablebase.Table, name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	annotations, err := tablebase.ReadAnnotations(file)
+	if err != nil {
+		return err
+	}
+
+	errs := table.CheckAnnotations(annotations)
+	for _, err := range errs {
+		fmt.Fprintln(w, err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d entries failed", len(errs), len(table.Positions()))
+	}
+
+	fmt.Fprintf(w, "all %d entries match\n", len(annotations))
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/engine"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "levels",
+		args:    "[-games n] [-seed seed]",
+		summary: "measure the strength of each engine level",
+		run:     levels,
+	})
+}
+
+// levels implements the levels subcommand, which measures the strength of
+// each engine level by how often it errs in the positions of the tablebase
+// and by it's results in a match against perfect play.
+func levels(e *env, args []string) error {
+	flags := e.flagSet("levels")
+	games := flags.Int("games", 100, "number of games against perfect play")
+	seed := flags.Int64("seed", 1, "seed for the engines")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	table := tablebase.Generate()
+	perfect := engine.NewPerfect(table, *seed)
+
+	var start board.Board // zero value is starting board
+
+	fmt.Fprintln(e.stdout, "Level : Errors          : Result vs perfect")
+	for level := engine.MinLevel; level <= engine.MaxLevel; level++ {
+		player, _ := engine.NewLevel(table, level, *seed)
+
+		// count the positions where the level plays a worse move
+		var errors, positions int
+		for _, data := range table.Positions() {
+			position := data.Position()
+			if position.State() != board.Unfinished {
+				continue
+			}
+
+			positions++
+			if penalty, _ := data.Penalty(player.Move(position)); penalty > 0 {
+				errors++
+			}
+		}
+
+		result := engine.Match(start, player, perfect, *games)
+		fmt.Fprintf(e.stdout, "%5d : %4d/%d (%2.0f%%) : +%d =%d -%d\n",
+			level, errors, positions, float64(errors)/float64(positions)*100,
+			result.Wins, result.Draws, result.Losses)
+	}
+
+	return nil
+}
@@ -14,134 +14,64 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
 	"laptudirm.com/x/wreck/pkg/board"
-	"laptudirm.com/x/wreck/pkg/tablebase"
 )
 
 func main() {
-	if len(os.Args) > 2 {
-		fmt.Fprintln(os.Stderr, "usage: wreck [position]")
-		os.Exit(1)
-	}
+	os.Exit(run(osEnv(), os.Args[1:]))
+}
 
-	position := `.........`
-	if len(os.Args) == 2 {
-		position = os.Args[1]
+// run runs the subcommand given by the first of the given arguments in the
+// given environment, and returns the exit status of wreck. The repl is run
+// if no subcommand is provided. The status is 2 if the subcommand or it's
+// arguments are invalid, and 1 if it fails.
+func run(e *env, args []string) int {
+	c := commands["repl"]
+	if len(args) > 0 {
+		subcommand, found := commands[args[0]]
+		switch {
+		case found:
+			c, args = subcommand, args[1:]
+		case !strings.HasPrefix(args[0], "-") && !board.IsValidPosition(args[0]):
+			// neither a subcommand nor the repl's position
+			fmt.Fprintf(e.stderr, "wreck: unknown command %#v\n", args[0])
+			fmt.Fprintln(e.stderr, "Run 'wreck help' for a list of commands.")
+			return 2
+		}
 	}
 
-	b, err := board.New(position)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	switch err := c.run(e, args); err {
+	case nil, flag.ErrHelp:
+		return 0
+	case errUsage:
+		return 2
+	default:
+		fmt.Fprintln(e.stderr, "wreck:", err)
+		return 1
 	}
+}
 
-	table := tablebase.Generate()
-
-	fmt.Println("The Wreck Tic-Tac-Toe Engine")
-	fmt.Println("Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>")
-	fmt.Println("Licensed under the Apache License, Version 2.0")
-	fmt.Println("\nType 'help' for help regarding commands")
-
-	reader := bufio.NewReader(os.Stdin)
-commands:
-	for {
-		fmt.Print("\nwreck :: ")
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Fprintln(os.Stderr, "wreck: error reading from stdin")
-			os.Exit(1)
-		}
-		fmt.Println()
-
-		args := strings.Split(strings.Trim(input, "\n\r\t "), " ")
-
-		if len(args) == 0 {
-			continue commands
-		}
-
-		switch args[0] {
-		case "exit":
-			break commands
-
-		case "load":
-			if len(args) != 2 {
-				fmt.Println("wreck: usage: load <position>")
-				break
-			}
-
-			b, err = board.New(args[1])
-			if err != nil {
-				fmt.Println(err)
-				break
-			}
-
-			if data, found := table.Search(b); found {
-				fmt.Print(data.String())
-			} else {
-				fmt.Println("wreck: current position not found in tablebase")
-			}
-
-		case "play":
-			switch {
-			case len(args) != 2:
-				fmt.Println("wreck: usage: play <move>")
-			case len(args[1]) != 1:
-				fmt.Printf("wreck: %#v is not a valid move\n", args[1])
-			default:
-				move := args[1][0]
-				err := b.Play(board.Move(move - 48))
-				if err != nil {
-					fmt.Println(err)
-					break
-				}
-
-				if data, found := table.Search(b); found {
-					fmt.Print(data.String())
-				} else {
-					fmt.Println("wreck: current position not found in tablebase")
-				}
-			}
-
-		case "eval":
-			if len(args) != 1 {
-				fmt.Println("wreck: usage: eval")
-				break
-			}
-
-			if data, found := table.Search(b); found {
-				fmt.Print(data.String())
-			} else {
-				fmt.Println("wreck: current position not found in tablebase")
-			}
-
-		case "help":
-			helpString := `Commands:
-  load <position>   Load the given position into wreck
-  play <move>       Play the given move on the current position
-  eval              Evaluate the current position and show data
-  exit              Exit from the repl
-
-Position String (<position>):
-  A position in wreck is represented by a 9-character string which is
-  composed of the symbols x, o, and . which represent a mark by player x, a
-  mark by player o, and an empty cell. Each character represents a cell in
-  the tic tac toe board.
+// parsePosition parses the optional position argument of a command, which
+// defaults to the starting position.
+func parsePosition(args []string) (board.Board, error) {
+	if len(args) == 0 {
+		return board.Board{}, nil
+	}
 
-Moves (<move>):
-  Moves are represented by the numbers 1-9 where each number represents a
-  position in the tic tac toe board.
-    1 2 3
-    4 5 6
-    7 8 9`
-			fmt.Println(helpString)
+	return board.New(args[0])
+}
 
-		default:
-			fmt.Printf("wreck: unknown command %#v\n", args[0])
-		}
+// listMoves converts the given moves into a space separated list in the
+// given notation, or "none" if there aren't any moves.
+func listMoves(moves []board.Move, notation board.Notation) string {
+	if len(moves) == 0 {
+		return "none"
 	}
+
+	return notation.FormatMoves(moves)
 }
@@ -0,0 +1,175 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/engine"
+	"laptudirm.com/x/wreck/pkg/nn"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "nn",
+		args:    "train|test|match [flags]",
+		summary: "train, test, and play matches with neural networks",
+		run:     nnCmd,
+	})
+}
+
+// nnCmd implements the nn subcommand, which trains neural networks on the
+// tablebase, tests their accuracy, and plays matches with them.
+func nnCmd(e *env, args []string) error {
+	if len(args) == 0 {
+		e.usage("nn")
+		return errUsage
+	}
+
+	switch args[0] {
+	case "train":
+		return nnTrain(e, args[1:])
+	case "test":
+		return nnTest(e, args[1:])
+	case "match":
+		return nnMatch(e, args[1:])
+	default:
+		e.usage("nn")
+		return errUsage
+	}
+}
+
+// nnTrain trains a new network on the tablebase and saves it's weights.
+func nnTrain(e *env, args []string) error {
+	config := nn.DefaultConfig
+
+	flags := e.flagSet("nn")
+	hidden := flags.Int("hidden", 32, "number of hidden neurons")
+	flags.IntVar(&config.Epochs, "epochs", config.Epochs, "number of passes over the tablebase")
+	flags.Float64Var(&config.LearningRate, "rate", config.LearningRate, "learning rate")
+	flags.Int64Var(&config.Seed, "seed", config.Seed, "seed for initializing and training the network")
+	output := flags.String("o", "", "write the weights to this file")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *output == "" || *hidden < 1 {
+		flags.Usage()
+		return errUsage
+	}
+
+	records := tableRecords(tablebase.Generate(), false)
+
+	network := nn.New(*hidden, config.Seed)
+	network.Train(records, config, func(epoch int, loss float64) {
+		if epoch%10 == 0 || epoch == config.Epochs {
+			fmt.Fprintf(e.stderr, "epoch %d: loss %.4f\n", epoch, loss)
+		}
+	})
+
+	printReport(e.stdout, network.Test(records))
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := network.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// nnTest reports the accuracy of a network against the tablebase.
+func nnTest(e *env, args []string) error {
+	flags := e.flagSet("nn")
+	weights := flags.String("w", "", "read the weights from this file")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *weights == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	network, err := loadNetwork(*weights)
+	if err != nil {
+		return err
+	}
+
+	printReport(e.stdout, network.Test(tableRecords(tablebase.Generate(), false)))
+	return nil
+}
+
+// nnMatch plays matches between a network and the perfect and random
+// players.
+func nnMatch(e *env, args []string) error {
+	flags := e.flagSet("nn")
+	weights := flags.String("w", "", "read the weights from this file")
+	games := flags.Int("games", 100, "number of games against each opponent")
+	seed := flags.Int64("seed", 1, "seed for the opponents")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *weights == "" {
+		flags.Usage()
+		return errUsage
+	}
+
+	network, err := loadNetwork(*weights)
+	if err != nil {
+		return err
+	}
+
+	player := network.Player()
+	opponents := []engine.Player{
+		engine.NewPerfect(tablebase.Generate(), *seed),
+		engine.NewRandom(*seed),
+	}
+
+	var start board.Board // zero value is starting board
+	for _, opponent := range opponents {
+		result := engine.Match(start, player, opponent, *games)
+		fmt.Fprintf(e.stdout, "%s vs %s: +%d =%d -%d (%.1f%%)\n",
+			player.Name(), opponent.Name(),
+			result.Wins, result.Draws, result.Losses, result.Score()*100)
+	}
+
+	return nil
+}
+
+// loadNetwork loads a network from the weights file with the given name.
+func loadNetwork(name string) (*nn.Network, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return nn.Load(file)
+}
+
+// printReport prints the given accuracy report of a network to w.
+func printReport(w io.Writer, report nn.Report) {
+	fmt.Fprintf(w, "Results : %d/%d (%.1f%%)\n", report.Correct, report.Positions, report.ResultAccuracy()*100)
+	fmt.Fprintf(w, "Moves   : %d/%d (%.1f%%)\n", report.BestMoves, report.Moves, report.MoveAccuracy()*100)
+}
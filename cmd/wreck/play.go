@@ -0,0 +1,137 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/engine"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "play",
+		args:    "[-level n] [-seed seed] [-as x|o] [-notation notation] [position]",
+		summary: "play a game against the engine",
+		run:     play,
+	})
+}
+
+// play implements the play subcommand, which plays a game between the user
+// and the engine, reading the user's moves from the environment's input.
+func play(e *env, args []string) error {
+	flags := e.flagSet("play")
+	level := flags.Int("level", engine.MaxLevel, "strength level of the engine, from 1 to 10")
+	seed := flags.Int64("seed", 1, "seed for the engine")
+	as := flags.String("as", "x", "player to play as (x, o)")
+	notationName := flags.String("notation", "numeric", "notation of the moves in the output (numeric, algebraic, rowcol)")
+	if err := e.parse(flags, args, 0, 1); err != nil {
+		return err
+	}
+
+	var human board.Player
+	switch *as {
+	case "x":
+		human = board.PlayerX
+	case "o":
+		human = board.PlayerO
+	default:
+		return fmt.Errorf("unknown player %#v", *as)
+	}
+
+	notation, found := board.ParseNotation(*notationName)
+	if !found {
+		return fmt.Errorf("unknown notation %#v", *notationName)
+	}
+
+	b, err := parsePosition(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	player, err := engine.NewLevel(tablebase.GenerateFrom(b), *level, *seed)
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(e.stdin)
+	for b.State() == board.Unfinished {
+		fmt.Fprintf(e.stdout, "%s\n\n", b)
+
+		if b.Turn() != human {
+			move := player.Move(b)
+			if err := b.Play(move); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(e.stdout, "%s plays %s\n\n", player.Name(), notation.FormatMove(move))
+			continue
+		}
+
+		move, err := readMove(e.stdout, in, b, notation)
+		switch {
+		case err == io.EOF:
+			fmt.Fprintln(e.stdout)
+			return nil
+		case err != nil:
+			return err
+		case move == 0:
+			// the user resigned
+			fmt.Fprintf(e.stdout, "%s wins\n", human.Other())
+			return nil
+		}
+
+		b.Play(move)
+		fmt.Fprintln(e.stdout)
+	}
+
+	fmt.Fprintf(e.stdout, "%s\n\n%s\n", b, b.State())
+	return nil
+}
+
+// readMove prompts the user for a valid move in the given position till
+// one is provided, listing the valid moves in the given notation. It returns
+// 0 if the user resigns the game.
+func readMove(out io.Writer, in *bufio.Reader, b board.Board, notation board.Notation) (board.Move, error) {
+	for {
+		fmt.Fprintf(out, "your move (%s): ", listMoves(b.ValidMoves(), notation))
+
+		line, err := in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return 0, err
+		}
+
+		input := strings.TrimSpace(line)
+		if input == "resign" {
+			return 0, nil
+		}
+
+		move, err := parseMove(input)
+		if err == nil && !b.IsValidMove(move) {
+			err = fmt.Errorf("wreck: %s is not a legal move", input)
+		}
+
+		if err != nil {
+			fmt.Fprintln(out, err)
+			continue
+		}
+
+		return move, nil
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "puzzles",
+		args:    "[-kind kind] [-o file]",
+		summary: "export the puzzle positions of the tablebase",
+		run:     puzzles,
+	})
+}
+
+// puzzles implements the puzzles subcommand, which scans the tablebase for
+// puzzle positions and exports them as a puzzle set, one puzzle per line.
+func puzzles(e *env, args []string) error {
+	flags := e.flagSet("puzzles")
+	kindName := flags.String("kind", "", "only export puzzles of this kind (only-move, most-lose, pitfall, fork)")
+	output := flags.String("o", "", "write the puzzle set to this file instead of stdout")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	var filter func(tablebase.PuzzleKind) bool
+	if *kindName == "" {
+		filter = func(tablebase.PuzzleKind) bool { return true }
+	} else {
+		kind, found := tablebase.ParsePuzzleKind(*kindName)
+		if !found {
+			return fmt.Errorf("unknown puzzle kind %#v", *kindName)
+		}
+
+		filter = func(k tablebase.PuzzleKind) bool { return k == kind }
+	}
+
+	table := tablebase.Generate()
+	if *output == "" {
+		return writePuzzles(e.stdout, table, filter)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+
+	if err := writePuzzles(file, table, filter); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// writePuzzles writes the puzzles of the tablebase whose kind is included
+// by the filter to w, one puzzle per line.
+func writePuzzles(w io.Writer, table *tablebase.Table, filter func(tablebase.PuzzleKind) bool) error {
+	buffer := bufio.NewWriter(w)
+	for _, puzzle := range table.Puzzles() {
+		if filter(puzzle.Kind) {
+			fmt.Fprintln(buffer, puzzle)
+		}
+	}
+
+	return buffer.Flush()
+}
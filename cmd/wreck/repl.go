@@ -0,0 +1,571 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/engine"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "repl",
+		args:    "[-c commands | -f file] [position]",
+		summary: "run the read-eval-print loop, or a script of it's commands",
+		run:     repl,
+	})
+}
+
+// repl implements the repl subcommand, which runs wreck's read-eval-print
+// loop on a position. The commands are read from the given file or string
+// if provided, otherwise from the standard input till the user exits or the
+// input ends. If the commands are not typed by a user, they are run as a
+// script, and an error is returned if any of them failed.
+func repl(e *env, args []string) error {
+	flags := e.flagSet("repl")
+	commands := flags.String("c", "", "run the given commands, separated by ;, and exit")
+	file := flags.String("f", "", "run the commands in the given file and exit")
+	if err := e.parse(flags, args, 0, 1); err != nil {
+		return err
+	}
+
+	if *commands != "" && *file != "" {
+		e.usage("repl")
+		return errUsage
+	}
+
+	b, err := parsePosition(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *commands != "":
+		return script(e, b, func(s *session) error {
+			return s.execute(*commands, "")
+		})
+
+	case *file != "":
+		in, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		return script(e, b, func(s *session) error {
+			return s.run(in, *file)
+		})
+
+	case !e.isTerminal():
+		return script(e, b, func(s *session) error {
+			return s.run(e.stdin, "")
+		})
+	}
+
+	s := newSession(b, e.stdout, e.stdout, true)
+	s.editor = newEditor(e.stdin.(*os.File), e.stdout, historyFile())
+	s.editor.complete = s.complete
+
+	fmt.Fprintln(e.stdout, "The Wreck Tic-Tac-Toe Engine")
+	fmt.Fprintln(e.stdout, "Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>")
+	fmt.Fprintln(e.stdout, "Licensed under the Apache License, Version 2.0")
+	fmt.Fprintln(e.stdout, "\nType 'help' for help regarding commands")
+
+	if err := s.run(e.stdin, ""); err != nil && err != errExit {
+		return err
+	}
+
+	return nil
+}
+
+// script runs a non-interactive session on the given position, where the
+// given function runs the session's commands. It returns an error if any
+// of the commands failed.
+func script(e *env, b board.Board, run func(s *session) error) error {
+	s := newSession(b, e.stdout, e.stderr, false)
+	if err := run(s); err != nil && err != errExit {
+		return err
+	}
+
+	if s.failed {
+		return fmt.Errorf("script failed")
+	}
+
+	return nil
+}
+
+// maxSourceDepth is the maximum number of nested source commands, which
+// prevents scripts which source themselves from running forever.
+const maxSourceDepth = 16
+
+// errExit is returned by the exit command to end the session.
+var errExit = errors.New("exit")
+
+// session represents the state of a repl session, which executes commands
+// read from the user or from scripts.
+type session struct {
+	table *tablebase.Table
+	board board.Board
+
+	// engine used to play moves, which starts at full strength
+	level  int
+	player engine.Player
+
+	notation board.Notation // notation of the moves in the output
+
+	out         io.Writer // output of the commands
+	errOut      io.Writer // errors reported by the commands
+	interactive bool      // whether the commands are typed by a user
+	editor      *editor   // line editor for interactive sessions, if any
+
+	failed bool // whether any command has failed
+	depth  int  // number of nested source commands being run
+}
+
+// newSession creates a new session on the given position, which writes the
+// output of it's commands to out and the errors to errOut. An interactive
+// session prints a prompt before reading each line.
+func newSession(b board.Board, out, errOut io.Writer, interactive bool) *session {
+	table := tablebase.Generate()
+	player, _ := engine.NewLevel(table, engine.MaxLevel, 1)
+
+	return &session{
+		table:       table,
+		board:       b,
+		level:       engine.MaxLevel,
+		player:      player,
+		out:         out,
+		errOut:      errOut,
+		interactive: interactive,
+	}
+}
+
+// run reads commands from the given reader and executes them, till the
+// input ends or the exit command is run, in which case it returns errExit.
+// Errors reported by the commands are prefixed with the line they are on
+// if the given name of the input is not empty.
+func (s *session) run(in io.Reader, name string) error {
+	reader := bufio.NewReader(in)
+	for line := 1; ; line++ {
+		input, err := s.readLine(reader)
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		location := ""
+		if name != "" {
+			location = fmt.Sprintf("%s:%d: ", name, line)
+		}
+
+		if s.execute(input, location) == errExit {
+			return errExit
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// readLine reads a line of commands from the given reader. If the session
+// is interactive, a prompt is printed before the line, and the line is read
+// with the session's line editor if it has one.
+func (s *session) readLine(reader *bufio.Reader) (string, error) {
+	if !s.interactive {
+		return reader.ReadString('\n')
+	}
+
+	var input string
+	var err error
+	if s.editor != nil {
+		fmt.Fprintln(s.out)
+		input, err = s.editor.readLine("wreck :: ")
+	} else {
+		fmt.Fprint(s.out, "\nwreck :: ")
+		input, err = reader.ReadString('\n')
+	}
+
+	fmt.Fprintln(s.out)
+	return input, err
+}
+
+// complete returns the completions of the last word of the given text for
+// the line editor. The first word of a command is completed with the names
+// of the commands, and the argument of play and why with the legal moves.
+func (s *session) complete(text string) []string {
+	// only the last command on the line is completed
+	text = text[strings.LastIndexByte(text, ';')+1:]
+
+	words := strings.Fields(text)
+	word := lastWord(text)
+	if word == "" {
+		words = append(words, "")
+	}
+
+	var candidates []string
+	switch {
+	case len(words) == 1:
+		for _, c := range replCommands {
+			candidates = append(candidates, c.name)
+		}
+
+	case len(words) == 2 && (words[0] == "play" || words[0] == "why"):
+		for _, move := range s.board.ValidMoves() {
+			candidates = append(candidates, s.notation.FormatMove(move))
+		}
+	}
+
+	var completions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			completions = append(completions, candidate)
+		}
+	}
+
+	return completions
+}
+
+// execute executes the commands in the given line, which are separated by
+// semicolons. Anything after a # is a comment and is ignored. Errors are
+// reported with the given location prefixed. It returns errExit if one of
+// the commands is exit, without executing the rest.
+func (s *session) execute(line, location string) error {
+	if comment := strings.IndexByte(line, '#'); comment != -1 {
+		line = line[:comment]
+	}
+
+	for _, command := range strings.Split(line, ";") {
+		args := strings.Fields(command)
+		if len(args) == 0 {
+			continue
+		}
+
+		err := s.command(args)
+		switch {
+		case err == errExit:
+			return errExit
+		case err != nil:
+			s.failed = true
+			fmt.Fprintf(s.errOut, "%s%s\n", location, err)
+		}
+	}
+
+	return nil
+}
+
+// replCommand represents one of the commands of the repl.
+type replCommand struct {
+	name    string // name used to run the command
+	args    string // usage of the command's arguments
+	summary string // short description of the command
+
+	// minArgs and maxArgs are the minimum and maximum number of arguments
+	// following the command's name
+	minArgs, maxArgs int
+
+	// run runs the command in the given session, with the arguments
+	// following the command's name.
+	run func(s *session, args []string) error
+}
+
+// usage returns the usage of the command, i.e, it's name and arguments.
+func (c replCommand) usage() string {
+	if c.args == "" {
+		return c.name
+	}
+
+	return c.name + " " + c.args
+}
+
+// replCommands is the registry of the repl's commands, in the order they
+// are listed in the help. It is initialized in init, since the help command
+// refers to it.
+var replCommands []replCommand
+
+func init() {
+	replCommands = []replCommand{
+		{"load", "<position> [x|o]", "Load the given position, with the given player to move in a custom setup", 1, 2, loadCmd},
+		{"play", "<move>", "Play the given move on the current position", 1, 1, playCmd},
+		{"eval", "", "Evaluate the current position and show data", 0, 0, evalCmd},
+		{"level", "[<level> [<seed>]]", "Show or set the engine's strength level, from 1 to 10", 0, 2, levelCmd},
+		{"go", "", "Play the engine's move on the current position", 0, 0, goCmd},
+		{"hint", "", "Show one of the best moves, without the evaluation", 0, 0, hintCmd},
+		{"why", "<move>", "Explain the given move with the line that follows it", 1, 1, whyCmd},
+		{"threats", "", "Show the threats and forks of each player", 0, 0, threatsCmd},
+		{"notation", "[numeric|algebraic|rowcol]", "Show or set the notation of moves in the output", 0, 1, notationCmd},
+		{"source", "<file>", "Run the commands in the given file", 1, 1, sourceCmd},
+		{"help", "", "Show help regarding commands", 0, 0, helpCmd},
+		{"exit", "", "Exit from the repl", 0, 0, func(*session, []string) error { return errExit }},
+	}
+}
+
+// command executes the command with the given arguments, where the first
+// argument is the name of the command.
+func (s *session) command(args []string) error {
+	for _, c := range replCommands {
+		if c.name != args[0] {
+			continue
+		}
+
+		if len(args)-1 < c.minArgs || len(args)-1 > c.maxArgs {
+			return fmt.Errorf("wreck: usage: %s", c.usage())
+		}
+
+		return c.run(s, args[1:])
+	}
+
+	return fmt.Errorf("wreck: unknown command %#v", args[0])
+}
+
+// loadCmd implements the load command.
+func loadCmd(s *session, args []string) error {
+	var b board.Board
+	var err error
+
+	switch {
+	case len(args) == 1:
+		b, err = board.New(args[0])
+	case args[1] == "x":
+		b, err = board.NewSetup(args[0], board.PlayerX)
+	case args[1] == "o":
+		b, err = board.NewSetup(args[0], board.PlayerO)
+	default:
+		return fmt.Errorf("wreck: usage: load <position> [x|o]")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// custom setups may be missing from the tablebase
+	if _, found := s.table.SearchOrGenerate(b); !found {
+		return fmt.Errorf("wreck: position %s is not legal", b.PositionString())
+	}
+
+	s.board = b
+	return s.printPosition()
+}
+
+// playCmd implements the play command.
+func playCmd(s *session, args []string) error {
+	move, err := parseMove(args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := s.board.Play(move); err != nil {
+		return err
+	}
+
+	return s.printPosition()
+}
+
+// evalCmd implements the eval command.
+func evalCmd(s *session, args []string) error {
+	return s.printPosition()
+}
+
+// levelCmd implements the level command.
+func levelCmd(s *session, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintf(s.out, "level %d\n", s.level)
+		return nil
+	}
+
+	level, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("wreck: %#v is not a valid level", args[0])
+	}
+
+	seed := int64(1)
+	if len(args) == 2 {
+		if seed, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+			return fmt.Errorf("wreck: %#v is not a valid seed", args[1])
+		}
+	}
+
+	player, err := engine.NewLevel(s.table, level, seed)
+	if err != nil {
+		return err
+	}
+
+	s.level, s.player = level, player
+	fmt.Fprintf(s.out, "level %d\n", s.level)
+	return nil
+}
+
+// goCmd implements the go command.
+func goCmd(s *session, args []string) error {
+	if s.board.State() != board.Unfinished {
+		return fmt.Errorf("wreck: game has finished")
+	}
+
+	move := s.player.Move(s.board)
+	if err := s.board.Play(move); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(s.out, "%s plays %s\n", s.player.Name(), s.notation.FormatMove(move))
+
+	return s.printPosition()
+}
+
+// hintCmd implements the hint command.
+func hintCmd(s *session, args []string) error {
+	h, err := hint(s.table, s.board, s.notation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(s.out, h)
+	return nil
+}
+
+// whyCmd implements the why command.
+func whyCmd(s *session, args []string) error {
+	move, err := parseMove(args[0])
+	if err != nil {
+		return err
+	}
+
+	explanation, err := why(s.table, s.board, move, s.notation)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(s.out, explanation)
+	return nil
+}
+
+// threatsCmd implements the threats command.
+func threatsCmd(s *session, args []string) error {
+	b := s.board
+	for _, player := range []board.Player{b.Turn(), b.Turn().Other()} {
+		fmt.Fprintf(s.out, "Player %s:\n", player)
+		fmt.Fprintf(s.out, "  Threats  : %s\n", listMoves(b.Threats(player), s.notation))
+		fmt.Fprintf(s.out, "  Forks    : %s\n", listMoves(b.ForkingMoves(player), s.notation))
+		fmt.Fprintf(s.out, "  Blocks   : %s\n", listMoves(b.BlockingMoves(player), s.notation))
+	}
+
+	return nil
+}
+
+// notationCmd implements the notation command.
+func notationCmd(s *session, args []string) error {
+	if len(args) == 1 {
+		notation, found := board.ParseNotation(args[0])
+		if !found {
+			return fmt.Errorf("wreck: unknown notation %#v", args[0])
+		}
+
+		s.notation = notation
+	}
+
+	fmt.Fprintf(s.out, "notation %s\n", s.notation)
+	return nil
+}
+
+// sourceCmd implements the source command.
+func sourceCmd(s *session, args []string) error {
+	if s.depth >= maxSourceDepth {
+		return fmt.Errorf("wreck: source: too many nested files")
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("wreck: %w", err)
+	}
+	defer file.Close()
+
+	// commands in the file are not typed by the user
+	interactive := s.interactive
+	s.interactive = false
+	s.depth++
+
+	err = s.run(file, args[0])
+
+	s.interactive = interactive
+	s.depth--
+	return err
+}
+
+// helpCmd implements the help command, whose list of commands is generated
+// from the registry.
+func helpCmd(s *session, args []string) error {
+	fmt.Fprintln(s.out, "Commands:")
+	for _, c := range replCommands {
+		if usage := c.usage(); len(usage) < 18 {
+			fmt.Fprintf(s.out, "  %-17s %s\n", usage, c.summary)
+		} else {
+			fmt.Fprintf(s.out, "  %s\n  %-17s %s\n", usage, "", c.summary)
+		}
+	}
+
+	fmt.Fprintln(s.out, helpString)
+	return nil
+}
+
+// printPosition prints the current position with it's data from the
+// tablebase.
+func (s *session) printPosition() error {
+	data, found := s.table.Search(s.board)
+	if !found {
+		return fmt.Errorf("wreck: current position not found in tablebase")
+	}
+
+	fmt.Fprint(s.out, data.Format(s.notation))
+	return nil
+}
+
+// parseMove parses a move argument, which may be written in any notation.
+func parseMove(s string) (board.Move, error) {
+	move, err := board.ParseMove(s)
+	if err != nil {
+		return 0, fmt.Errorf("wreck: %#v is not a valid move", s)
+	}
+
+	return move, nil
+}
+
+// helpString is the part of the help command's output which follows the
+// list of commands.
+const helpString = `
+Scripts:
+  Several commands can be written on a single line, separated by ;, and
+  anything after a # is a comment. Scripts can be run with wreck -c or
+  wreck -f, and wreck exits with an error if any of the commands fail.
+
+Position String (<position>):
+  A position in wreck is represented by a 9-character string which is
+  composed of the symbols x, o, and . which represent a mark by player x, a
+  mark by player o, and an empty cell. Each character represents a cell in
+  the tic tac toe board. The marks may also be written as X and O, empty
+  cells as - or _, and the rows may be separated by slashes, like xo./.x./...
+
+Moves (<move>):
+  Moves are represented by the numbers 1-9, by algebraic coordinates, or by
+  row,column pairs, where each represents a cell in the tic tac toe board.
+  The notation command selects which of them is used in the output.
+    1 2 3     a3 b3 c3     1,1 1,2 1,3
+    4 5 6     a2 b2 c2     2,1 2,2 2,3
+    7 8 9     a1 b1 c1     3,1 3,2 3,3`
@@ -0,0 +1,131 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "serve",
+		args:    "[-addr addr]",
+		summary: "serve evaluations of positions over http",
+		run:     serve,
+	})
+}
+
+// serve implements the serve subcommand, which runs an http server that
+// evaluates positions sent to it's /eval endpoint, like:
+//
+//	GET /eval?position=x...o....
+//
+// The response is a json object with the position's evaluation and the
+// evaluation of each of it's moves.
+func serve(e *env, args []string) error {
+	flags := e.flagSet("serve")
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	s := server{
+		table: tablebase.Generate(),
+		log:   log.New(e.stderr, "", log.LstdFlags),
+	}
+
+	s.log.Printf("listening on %s", *addr)
+	return http.ListenAndServe(*addr, s.handler())
+}
+
+// server stores the state of the http server.
+type server struct {
+	table *tablebase.Table // only searched, so safe for concurrent use
+	log   *log.Logger
+}
+
+// handler returns the http handler which routes requests to the server's
+// endpoints.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/eval", s.eval)
+	return mux
+}
+
+// evalResponse is the response of the /eval endpoint.
+type evalResponse struct {
+	Position  string         `json:"position"`
+	Turn      string         `json:"turn"`
+	State     string         `json:"state"`
+	Eval      string         `json:"eval"`
+	BestMoves []int          `json:"best_moves"`
+	Moves     []moveResponse `json:"moves"`
+}
+
+// moveResponse represents a move in an evalResponse.
+type moveResponse struct {
+	Move int    `json:"move"`
+	Eval string `json:"eval"`
+}
+
+// eval handles requests to the /eval endpoint.
+func (s *server) eval(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	b, err := board.New(r.URL.Query().Get("position"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, found := s.table.Search(b)
+	if !found {
+		http.Error(w, fmt.Sprintf("position %s is not legal", b.PositionString()), http.StatusBadRequest)
+		return
+	}
+
+	response := evalResponse{
+		Position:  b.PositionString(),
+		Turn:      b.Turn().String(),
+		State:     b.State().String(),
+		Eval:      data.AbsEval().String(),
+		BestMoves: []int{},
+		Moves:     []moveResponse{},
+	}
+
+	for _, move := range data.BestMoves() {
+		response.BestMoves = append(response.BestMoves, int(move))
+	}
+
+	for _, entry := range data.Moves() {
+		response.Moves = append(response.Moves, moveResponse{
+			Move: int(entry.Move()),
+			Eval: entry.Data().AbsEval().String(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.log.Printf("%s: %v", r.URL, err)
+	}
+}
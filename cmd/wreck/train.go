@@ -0,0 +1,243 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "train",
+		args:    "[-file puzzles] [-level level] [-seed seed] [-notation notation]",
+		summary: "quiz yourself on the best move in positions",
+		run:     train,
+	})
+}
+
+// train implements the train subcommand, which quizzes the user on the
+// best move in positions taken from the tablebase or from a puzzle file.
+func train(e *env, args []string) error {
+	flags := e.flagSet("train")
+	file := flags.String("file", "", "take positions from this puzzle file instead of the tablebase")
+	level := flags.String("level", "", "only use positions of this difficulty (easy, medium, hard)")
+	seed := flags.Int64("seed", 0, "seed for choosing positions (default: current time)")
+	notationName := flags.String("notation", "numeric", "notation of the moves in the output (numeric, algebraic, rowcol)")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	notation, found := board.ParseNotation(*notationName)
+	if !found {
+		return fmt.Errorf("unknown notation %#v", *notationName)
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	table := tablebase.Generate()
+
+	// collect the positions to train on
+	var positions []board.Board
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		puzzles, err := tablebase.ReadPuzzles(f)
+		if err != nil {
+			return err
+		}
+
+		for _, puzzle := range puzzles {
+			positions = append(positions, puzzle.Position)
+		}
+	} else {
+		for _, data := range table.Positions() {
+			positions = append(positions, data.Position())
+		}
+	}
+
+	if *level != "" && *level != "easy" && *level != "medium" && *level != "hard" {
+		return fmt.Errorf("unknown difficulty level %#v", *level)
+	}
+
+	t := trainer{
+		table:    table,
+		rand:     rand.New(rand.NewSource(*seed)),
+		notation: notation,
+
+		in:  bufio.NewReader(e.stdin),
+		out: e.stdout,
+	}
+
+	// filter out positions which are not suitable for training
+	for _, position := range positions {
+		data, found := table.Search(position)
+		if !found {
+			continue
+		}
+
+		// a position is only suitable if it has a move which is worse
+		// than the best move
+		moves := data.Moves()
+		if len(moves) < 2 || moves[0].Eval() == moves[len(moves)-1].Eval() {
+			continue
+		}
+
+		if *level == "" || difficulty(data.RelEval()) == *level {
+			t.positions = append(t.positions, position)
+		}
+	}
+
+	if len(t.positions) == 0 {
+		return fmt.Errorf("no positions available for training")
+	}
+
+	return t.run()
+}
+
+// trainer stores the state of a training session.
+type trainer struct {
+	table     *tablebase.Table
+	positions []board.Board // positions to train on
+	rand      *rand.Rand
+	notation  board.Notation // notation of the moves in the output
+
+	score  int // number of correct answers
+	total  int // number of answered positions
+	streak int // current streak of correct answers
+	best   int // best streak of correct answers
+
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// run runs the training session till the user quits or the input ends.
+func (t *trainer) run() error {
+	fmt.Fprintln(t.out, "Find the best move in each position. Type 'skip' to skip a")
+	fmt.Fprintln(t.out, "position and 'quit' to end the training session.")
+
+	for {
+		position := t.positions[t.rand.Intn(len(t.positions))]
+		if done, err := t.ask(position); done || err != nil {
+			t.summary()
+			return err
+		}
+	}
+}
+
+// ask presents the given position to the user and checks their answer.
+// It returns true as the first argument if the user ended the session.
+func (t *trainer) ask(position board.Board) (bool, error) {
+	data, _ := t.table.Search(position)
+
+	fmt.Fprintf(t.out, "\n%s\n", position)
+	if position.XsTurn() {
+		fmt.Fprintln(t.out, "[x to play]")
+	} else {
+		fmt.Fprintln(t.out, "[o to play]")
+	}
+
+	for {
+		fmt.Fprint(t.out, "\ntrain :: ")
+		input, err := t.in.ReadString('\n')
+		if err == io.EOF {
+			fmt.Fprintln(t.out)
+			return true, nil
+		} else if err != nil {
+			return true, err
+		}
+
+		input = strings.TrimSpace(input)
+		switch input {
+		case "quit", "exit":
+			return true, nil
+		case "skip":
+			fmt.Fprintf(t.out, "best: %s\n", t.notation.FormatMoves(data.BestMoves()))
+			return false, nil
+		}
+
+		move, err := parseMove(input)
+		if err != nil {
+			fmt.Fprintf(t.out, "train: %#v is not a valid move\n", input)
+			continue
+		}
+
+		entry, found := data.Search(move)
+		if !found {
+			fmt.Fprintf(t.out, "train: %#v is not a valid move\n", input)
+			continue
+		}
+
+		t.total++
+		best := data.Moves()[0]
+
+		if entry.Eval() == best.Eval() {
+			t.score++
+			t.streak++
+			if t.streak > t.best {
+				t.best = t.streak
+			}
+
+			fmt.Fprintf(t.out, "correct! %s leads to %s\n", t.notation.FormatMove(move), entry.Data().AbsEval())
+		} else {
+			t.streak = 0
+
+			fmt.Fprintf(t.out, "wrong: %s leads to %s\n", t.notation.FormatMove(move), entry.Data().AbsEval())
+			if line := entry.Data().Line(); len(line) > 0 {
+				fmt.Fprintf(t.out, "refutation: %s\n", t.notation.FormatMoves(line))
+			}
+
+			fmt.Fprintf(t.out, "best: %s leading to %s\n", t.notation.FormatMoves(data.BestMoves()), best.Data().AbsEval())
+			line := append([]board.Move{best.Move()}, best.Data().Line()...)
+			fmt.Fprintf(t.out, "line: %s\n", t.notation.FormatMoves(line))
+		}
+
+		fmt.Fprintf(t.out, "score: %d/%d, streak: %d\n", t.score, t.total, t.streak)
+		return false, nil
+	}
+}
+
+// summary prints the final results of the training session.
+func (t *trainer) summary() {
+	fmt.Fprintf(t.out, "\nfinal score: %d/%d, best streak: %d\n", t.score, t.total, t.best)
+}
+
+// difficulty classifies a position according to it's evaluation. Positions
+// with a short win are easier to solve than ones with a long win, while
+// drawn positions require the user to see the game till the end.
+func difficulty(eval evaluation.Rel) string {
+	switch steps := eval.Steps(); {
+	case eval.Outcome() == evaluation.Won && steps <= 2:
+		return "easy"
+	case eval.Outcome() == evaluation.Won && steps <= 3:
+		return "medium"
+	default:
+		return "hard"
+	}
+}
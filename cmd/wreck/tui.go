@@ -0,0 +1,342 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+// ansi escape sequences used by the terminal ui
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l" // use alternate screen, hide cursor
+	exitScreen  = "\x1b[?25h\x1b[?1049l" // show cursor, use main screen
+	clearScreen = "\x1b[2J\x1b[H"        // clear screen, move cursor home
+
+	styleReset   = "\x1b[0m"
+	styleX       = "\x1b[1;31m" // bold red
+	styleO       = "\x1b[1;36m" // bold cyan
+	styleCursor  = "\x1b[7m"    // reverse video
+	styleLast    = "\x1b[4m"    // underline
+	styleWinning = "\x1b[42m"   // green background
+	styleDim     = "\x1b[2m"    // faint
+
+	// move grades
+	styleInaccuracy = "\x1b[33m" // yellow
+	styleMistake    = "\x1b[35m" // magenta
+	styleBlunder    = "\x1b[31m" // red
+)
+
+func init() {
+	register(&command{
+		name:    "tui",
+		args:    "[-notation notation] [position]",
+		summary: "play and analyse a position in a full-screen terminal ui",
+		run:     tui,
+	})
+}
+
+// tui implements the tui subcommand, which runs a full-screen terminal ui
+// for playing and analysing a position. It falls back to the repl if the
+// environment's input or output is not a terminal.
+func tui(e *env, args []string) error {
+	flags := e.flagSet("tui")
+	notationName := flags.String("notation", "numeric", "notation of the moves in the ui (numeric, algebraic, rowcol)")
+	if err := e.parse(flags, args, 0, 1); err != nil {
+		return err
+	}
+
+	notation, found := board.ParseNotation(*notationName)
+	if !found {
+		return fmt.Errorf("unknown notation %#v", *notationName)
+	}
+
+	b, err := parsePosition(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	if !e.isTerminal() {
+		return repl(e, flags.Args())
+	}
+
+	restore, err := makeRaw(e.stdin.(*os.File).Fd())
+	if err != nil {
+		// fall back to the repl where raw mode is not available
+		return repl(e, flags.Args())
+	}
+	defer restore()
+
+	fmt.Fprint(e.stdout, enterScreen)
+	defer fmt.Fprint(e.stdout, exitScreen)
+
+	table := tablebase.Generate()
+	s := screen{
+		table: table,
+		start: b,
+		board: b,
+
+		cursor:   5,
+		notation: notation,
+
+		in:  bufio.NewReader(e.stdin),
+		out: e.stdout,
+	}
+
+	return s.run()
+}
+
+// screen stores the state of the terminal ui.
+type screen struct {
+	table *tablebase.Table
+
+	start   board.Board   // starting position
+	board   board.Board   // current position
+	history []board.Board // previous positions, for undoing moves
+	moves   []board.Move  // moves played from the starting position
+
+	cursor   board.Move     // cell under the cursor
+	message  string         // message shown below the board
+	notation board.Notation // notation of the moves and lines
+
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// run draws the ui and handles key presses till the user quits.
+func (s *screen) run() error {
+	for {
+		s.draw()
+
+		key, err := s.in.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		s.message = ""
+		switch key {
+		case 27: // escape sequence, arrow keys
+			// the bytes of an escape sequence arrive together, so a lone
+			// escape key press is not followed by buffered input
+			if s.in.Buffered() == 0 {
+				return nil
+			}
+
+			if next, _ := s.in.ReadByte(); next != '[' {
+				break
+			}
+
+			switch dir, _ := s.in.ReadByte(); dir {
+			case 'A':
+				s.moveCursor(-3)
+			case 'B':
+				s.moveCursor(3)
+			case 'C':
+				s.moveCursor(1)
+			case 'D':
+				s.moveCursor(-1)
+			}
+
+		// vim style movement keys
+		case 'k':
+			s.moveCursor(-3)
+		case 'j':
+			s.moveCursor(3)
+		case 'l':
+			s.moveCursor(1)
+		case 'h':
+			s.moveCursor(-1)
+
+		case '\r', '\n', ' ':
+			s.play(s.cursor)
+
+		case 'e':
+			// play the engine's best move
+			if data, found := s.table.Search(s.board); found && len(data.Moves()) > 0 {
+				s.play(data.Moves()[0].Move())
+			}
+
+		case 'u':
+			if len(s.history) > 0 {
+				s.board = s.history[len(s.history)-1]
+				s.history = s.history[:len(s.history)-1]
+				s.moves = s.moves[:len(s.moves)-1]
+			}
+
+		case 'n':
+			s.board = s.start
+			s.history = nil
+			s.moves = nil
+
+		case 'q', 3, 4: // q, ctrl-c, ctrl-d
+			return nil
+		}
+	}
+}
+
+// moveCursor moves the cursor by the given offset, keeping it inside the
+// board. Horizontal moves don't wrap around to the adjacent rows.
+func (s *screen) moveCursor(offset int) {
+	cursor := int(s.cursor) + offset
+	switch {
+	case cursor < 1, cursor > 9:
+		return
+	case offset == 1 && s.cursor%3 == 0, offset == -1 && cursor%3 == 0:
+		return
+	}
+
+	s.cursor = board.Move(cursor)
+}
+
+// play plays the given move on the current position.
+func (s *screen) play(move board.Move) {
+	previous := s.board
+	if err := s.board.Play(move); err != nil {
+		s.message = err.Error()
+		return
+	}
+
+	s.history = append(s.history, previous)
+	s.moves = append(s.moves, move)
+}
+
+// draw renders the ui to the screen.
+func (s *screen) draw() {
+	data, found := s.table.Search(s.board)
+
+	// render the board
+	pos := s.board.PositionString()
+	winning := s.board.WinningLines()
+
+	var grid []string
+	for row := 0; row < 3; row++ {
+		var cells []string
+		for col := 1; col <= 3; col++ {
+			cell := board.Move(row*3 + col)
+
+			var style string
+			switch pos[cell-1] {
+			case 'x':
+				style += styleX
+			case 'o':
+				style += styleO
+			}
+
+			for _, line := range winning {
+				if line.Has(cell) {
+					style += styleWinning
+					break
+				}
+			}
+
+			if len(s.moves) > 0 && cell == s.moves[len(s.moves)-1] {
+				style += styleLast
+			}
+
+			if cell == s.cursor {
+				style += styleCursor
+			}
+
+			symbol := string(pos[cell-1])
+			if symbol == "." {
+				symbol = " "
+			}
+
+			cells = append(cells, style+" "+symbol+" "+styleReset)
+		}
+
+		grid = append(grid, strings.Join(cells, "│"))
+		if row != 2 {
+			grid = append(grid, "───┼───┼───")
+		}
+	}
+
+	// render the moves and their evaluations
+	side := []string{"Move : Evaluation"}
+	if found {
+		for _, quality := range data.MoveQualities() {
+			child, _ := data.MoveData(quality.Move)
+			side = append(side, fmt.Sprintf("%s%4s : %s%s", gradeStyle(quality.Grade()), s.notation.FormatMove(quality.Move), child.AbsEval(), styleReset))
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString(clearScreen)
+	out.WriteString("The Wreck Tic-Tac-Toe Engine\r\n\r\n")
+
+	for i := 0; i < len(grid) || i < len(side); i++ {
+		line := strings.Repeat(" ", 11)
+		if i < len(grid) {
+			line = grid[i]
+		}
+
+		if i < len(side) {
+			line += "     " + side[i]
+		}
+
+		out.WriteString("  " + line + "\r\n")
+	}
+
+	out.WriteString("\r\n")
+	switch {
+	case !found:
+		out.WriteString("position not found in tablebase")
+	case len(winning) > 0:
+		var lines []string
+		for _, line := range winning {
+			lines = append(lines, s.notation.FormatLine(line))
+		}
+
+		out.WriteString(fmt.Sprintf("%s on %s, evaluation: %s", s.board.State(), strings.Join(lines, " and "), data.AbsEval()))
+	case s.board.State() != board.Unfinished:
+		out.WriteString(fmt.Sprintf("%s, evaluation: %s", s.board.State(), data.AbsEval()))
+	case s.board.XsTurn():
+		out.WriteString(fmt.Sprintf("x to play, evaluation: %s", data.AbsEval()))
+	default:
+		out.WriteString(fmt.Sprintf("o to play, evaluation: %s", data.AbsEval()))
+	}
+
+	out.WriteString("\r\nmoves: " + s.notation.FormatMoves(s.moves) + "\r\n")
+	if s.message != "" {
+		out.WriteString(s.message + "\r\n")
+	}
+
+	out.WriteString("\r\n" + styleDim)
+	out.WriteString("arrows/hjkl: move cursor  enter: play  e: engine move\r\n")
+	out.WriteString("u: undo  n: new game  q/esc: quit")
+	out.WriteString(styleReset)
+
+	io.WriteString(s.out, out.String())
+}
+
+// gradeStyle returns the style used to colour moves of the given grade.
+func gradeStyle(grade evaluation.Grade) string {
+	switch grade {
+	case evaluation.Inaccuracy:
+		return styleInaccuracy
+	case evaluation.Mistake:
+		return styleMistake
+	case evaluation.Blunder:
+		return styleBlunder
+	default:
+		return ""
+	}
+}
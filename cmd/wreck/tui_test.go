@@ -0,0 +1,201 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+// testScreen returns a screen showing the given position, which reads key
+// presses from the given input.
+func testScreen(t *testing.T, position, input string) (*screen, *bytes.Buffer) {
+	t.Helper()
+
+	b, err := board.New(position)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	return &screen{
+		table: tablebase.Generate(),
+		start: b,
+		board: b,
+
+		cursor: 5,
+
+		in:  bufio.NewReader(strings.NewReader(input)),
+		out: &out,
+	}, &out
+}
+
+// ansi matches the escape sequences used by the terminal ui.
+var ansi = regexp.MustCompile("\x1b\\[[0-9;?]*[a-zA-Z]")
+
+func TestMoveCursor(t *testing.T) {
+	tests := []struct {
+		from   board.Move
+		offset int
+		want   board.Move
+	}{
+		{5, -3, 2}, {5, 3, 8}, {5, 1, 6}, {5, -1, 4},
+
+		// edges of the board
+		{2, -3, 2}, {8, 3, 8}, {1, -1, 1}, {9, 1, 9},
+
+		// horizontal moves don't wrap around
+		{3, 1, 3}, {6, 1, 6}, {4, -1, 4}, {7, -1, 7},
+	}
+
+	for _, test := range tests {
+		s := screen{cursor: test.from}
+		s.moveCursor(test.offset)
+
+		if s.cursor != test.want {
+			t.Errorf("moveCursor(%d) from %d = %d, want %d", test.offset, test.from, s.cursor, test.want)
+		}
+	}
+}
+
+func TestScreenKeys(t *testing.T) {
+	// up, left, play, engine move, vim down, play, undo
+	s, _ := testScreen(t, ".........", "\x1b[A\x1b[D\rej\ruq")
+	if err := s.run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.cursor != 4 {
+		t.Errorf("cursor at %d, want 4", s.cursor)
+	}
+
+	if got := s.board.PositionString(); got != "x...o...." {
+		t.Errorf("position is %s, want x...o....", got)
+	}
+
+	if len(s.moves) != 2 || len(s.history) != 2 {
+		t.Errorf("moves %v with %d previous positions", s.moves, len(s.history))
+	}
+}
+
+func TestScreenIllegalMove(t *testing.T) {
+	s, out := testScreen(t, "....x....", "\rq")
+	if err := s.run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "invalid move 5") || len(s.moves) != 0 {
+		t.Errorf("illegal move wasn't reported:\n%s", out)
+	}
+}
+
+func TestScreenEscape(t *testing.T) {
+	// a lone escape quits without waiting for more input
+	s, _ := testScreen(t, ".........", "l\x1b")
+	if err := s.run(); err != nil {
+		t.Fatalf("run() = %v, want nil", err)
+	}
+
+	if s.cursor != 6 {
+		t.Errorf("cursor at %d, want 6", s.cursor)
+	}
+
+	// a new game after the escape sequence of the down arrow
+	s, _ = testScreen(t, "x........", "\x1b[Bnq")
+	if err := s.run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.cursor != 8 {
+		t.Errorf("cursor at %d, want 8", s.cursor)
+	}
+}
+
+func TestDraw(t *testing.T) {
+	s, out := testScreen(t, "xo.x.....", "")
+	s.draw()
+
+	screen := ansi.ReplaceAllString(out.String(), "")
+	for _, want := range []string{
+		" x │ o │   ",
+		"───┼───┼───",
+		"Move : Evaluation",
+		"   7 : +W3",
+		"   5 : +W2",
+		"o to play, evaluation: +W3",
+	} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("screen missing %#v:\n%s", want, screen)
+		}
+	}
+
+	// the cursor is drawn in reverse video
+	if !strings.Contains(out.String(), styleCursor+"   "+styleReset) {
+		t.Errorf("cursor not drawn:\n%q", out.String())
+	}
+}
+
+func TestDrawFinished(t *testing.T) {
+	s, out := testScreen(t, ".........", "")
+	for _, move := range []board.Move{1, 4, 2, 5, 3} {
+		s.play(move)
+	}
+
+	out.Reset()
+	s.draw()
+
+	screen := ansi.ReplaceAllString(out.String(), "")
+	if !strings.Contains(screen, "x wins on 1-2-3, evaluation: +W1") {
+		t.Errorf("result not drawn:\n%s", screen)
+	}
+
+	// the winning line is highlighted
+	if strings.Count(out.String(), styleWinning) != 3 {
+		t.Errorf("winning line not highlighted:\n%q", out.String())
+	}
+}
+
+func TestDrawNotation(t *testing.T) {
+	s, out := testScreen(t, "xo.......", "")
+	s.notation = board.Algebraic
+	for _, move := range []board.Move{5, 9, 7} {
+		s.play(move)
+	}
+
+	out.Reset()
+	s.draw()
+
+	screen := ansi.ReplaceAllString(out.String(), "")
+	for _, want := range []string{"moves: b2 c1 a1", "  c3 : +W2", "  a2 : +W2"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("screen missing %#v:\n%s", want, screen)
+		}
+	}
+
+	s.play(3)
+	s.play(4)
+	out.Reset()
+	s.draw()
+
+	screen = ansi.ReplaceAllString(out.String(), "")
+	if !strings.Contains(screen, "x wins on a3-a2-a1, evaluation: +W1") {
+		t.Errorf("winning line not drawn in algebraic notation:\n%s", screen)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"laptudirm.com/x/wreck/pkg/evaluation"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func init() {
+	register(&command{
+		name:    "tune",
+		args:    "[-lambda l]",
+		summary: "fit the weights of the heuristic evaluation to the tablebase",
+		run:     tune,
+	})
+}
+
+// tune implements the tune subcommand, which fits the weights of the
+// heuristic evaluation to the results of the tablebase.
+func tune(e *env, args []string) error {
+	flags := e.flagSet("tune")
+	lambda := flags.Float64("lambda", 1, "strength of the ridge regularization")
+	if err := e.parse(flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	var samples []evaluation.Sample
+	for _, data := range tablebase.Generate().Positions() {
+		samples = append(samples, evaluation.Sample{
+			Position: data.Position(),
+			Eval:     data.RelEval(),
+		})
+	}
+
+	weights := evaluation.Tune(samples, *lambda)
+
+	fmt.Fprintln(e.stdout, "Weights:")
+	for feature := evaluation.Feature(0); feature < evaluation.NumFeatures; feature++ {
+		fmt.Fprintf(e.stdout, "  %-10s %8.2f\n", feature, weights[feature])
+	}
+
+	fmt.Fprintln(e.stdout)
+	for _, fit := range []struct {
+		name    string
+		weights evaluation.Weights
+	}{
+		{"Default", evaluation.DefaultWeights},
+		{"Tuned", weights},
+	} {
+		f := fit.weights.Fit(samples)
+		fmt.Fprintf(e.stdout, "%-7s : rmse %.2f, accuracy %.1f%% of %d positions\n", fit.name, f.RMSE, f.Accuracy*100, f.Samples)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+// hint returns a hint for the player to move in the given position, which
+// names one of the best moves, in the given notation, without revealing the
+// evaluation.
+func hint(table *tablebase.Table, b board.Board, notation board.Notation) (string, error) {
+	if b.State() != board.Unfinished {
+		return "", fmt.Errorf("wreck: game has finished")
+	}
+
+	best := table.BestMoves(b)
+	if len(best) == 0 {
+		return "", fmt.Errorf("wreck: current position not found in tablebase")
+	}
+
+	return fmt.Sprintf("try playing %s", notation.FormatMove(best[0])), nil
+}
+
+// why explains the quality of the given move in the given position, by
+// showing how the game continues with perfect play after it. For moves
+// which aren't optimal, this is the line which refutes the move. Moves
+// which win or block a win are explained, and if the line contains a move
+// which creates a fork, the fork is explained. The moves are written in
+// the given notation.
+func why(table *tablebase.Table, b board.Board, move board.Move, notation board.Notation) (string, error) {
+	data, found := table.Search(b)
+	if !found {
+		return "", fmt.Errorf("wreck: current position not found in tablebase")
+	}
+
+	penalty, valid := data.Penalty(move)
+	if !valid {
+		return "", fmt.Errorf("wreck: %s is not a valid move", notation.FormatMove(move))
+	}
+
+	child, _ := data.MoveData(move)
+	bestChild, _ := data.MoveData(data.BestMoves()[0])
+
+	var s strings.Builder
+	switch grade := evaluation.GradePenalty(penalty); grade {
+	case evaluation.Best:
+		fmt.Fprintf(&s, "%s is a best move, leading to %s\n", notation.FormatMove(move), child.AbsEval())
+	default:
+		fmt.Fprintf(&s, "%s is %s %s, leading to %s instead of %s with %s\n",
+			notation.FormatMove(move), article(grade.String()), grade, child.AbsEval(),
+			bestChild.AbsEval(), notation.FormatMoves(data.BestMoves()))
+	}
+
+	// line played after the move with perfect play
+	line := append([]board.Move{move}, child.Line()...)
+
+	end := b
+	for _, m := range line {
+		end.Play(m)
+	}
+
+	if len(line) > 1 && penalty > 0 {
+		fmt.Fprintf(&s, "refutation: %s, %s\n", notation.FormatMoves(line), end.State())
+	} else {
+		fmt.Fprintf(&s, "continuation: %s, %s\n", notation.FormatMoves(line), end.State())
+	}
+
+	// explain the move itself if it wins or blocks a win
+	player, after := b.Turn(), b
+	after.Play(move)
+
+	if len(after.WinningLines()) > 0 {
+		fmt.Fprintf(&s, "%s completes a line, winning the game for %s\n", notation.FormatMove(move), player)
+		return s.String(), nil
+	}
+
+	for _, threat := range b.Threats(player.Other()) {
+		if threat == move {
+			fmt.Fprintf(&s, "%s blocks %s, who threatens to win on it\n", notation.FormatMove(move), player.Other())
+			break
+		}
+	}
+
+	// explain the first fork in the line, which usually decides the game
+	position := b
+	for _, m := range line {
+		player := position.Turn()
+		forks := position.ForkingMoves(player)
+		position.Play(m)
+
+		for _, fork := range forks {
+			if fork == m {
+				threats := position.Threats(player)
+				cells := strings.Replace(notation.FormatMoves(threats), " ", " and ", -1)
+				fmt.Fprintf(&s, "%s creates a fork for %s, who threatens to win on %s, which can't be blocked at once\n",
+					notation.FormatMove(m), player, cells)
+				return s.String(), nil
+			}
+		}
+	}
+
+	return s.String(), nil
+}
+
+// article returns the indefinite article used before the given word.
+func article(word string) string {
+	if strings.ContainsRune("aeiou", rune(word[0])) {
+		return "an"
+	}
+
+	return "a"
+}
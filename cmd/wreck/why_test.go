@@ -0,0 +1,111 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func TestHint(t *testing.T) {
+	table := tablebase.Generate()
+
+	b, _ := board.New("xo.x.....")
+	h, err := hint(table, b, board.Numeric)
+	if err != nil || h != "try playing 7" {
+		t.Errorf("hint() = %#v, %v", h, err)
+	}
+
+	b, _ = board.New("xxxoo....")
+	if _, err := hint(table, b, board.Numeric); err == nil {
+		t.Error("hint() on a finished game didn't fail")
+	}
+}
+
+func TestWhy(t *testing.T) {
+	tests := []struct {
+		position string
+		move     board.Move
+		want     []string
+	}{
+		// winning move
+		{"xx.oo....", 3, []string{
+			"3 is a best move, leading to +W1",
+			"continuation: 3, x wins",
+			"3 completes a line, winning the game for x",
+		}},
+		// blocking move, which doesn't stop a fork
+		{"xo.x.....", 7, []string{
+			"7 is a best move, leading to +W3",
+			"continuation: 7 5 3 6, x wins",
+			"7 blocks x, who threatens to win on it",
+			"5 creates a fork for x, who threatens to win on 6 and 9, which can't be blocked at once",
+		}},
+		// blocking move which gives up the win
+		{"xx.oo....", 6, []string{
+			"6 is a mistake, leading to ±00 instead of +W1 with 3",
+			"refutation: 6 3 7 8 9, draw",
+			"6 blocks o, who threatens to win on it",
+		}},
+		// losing move refuted by a fork
+		{"x.o.x..o.", 2, []string{
+			"2 is a blunder, leading to -W3 instead of +W1 with 9",
+			"refutation: 2 9 4 6, o wins",
+			"9 creates a fork for o, who threatens to win on 6 and 7, which can't be blocked at once",
+		}},
+	}
+
+	table := tablebase.Generate()
+	for _, test := range tests {
+		b, err := board.New(test.position)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		explanation, err := why(table, b, test.move, board.Numeric)
+		if err != nil {
+			t.Errorf("%s: why(%d): %v", test.position, test.move, err)
+			continue
+		}
+
+		want := strings.Join(test.want, "\n") + "\n"
+		if explanation != want {
+			t.Errorf("%s: why(%d) = %#v, want %#v", test.position, test.move, explanation, want)
+		}
+	}
+}
+
+func TestWhyNotation(t *testing.T) {
+	b, _ := board.New("xo.x.....")
+	explanation, err := why(tablebase.Generate(), b, 5, board.Algebraic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"b2 is an inaccuracy, leading to +W2 instead of +W3 with a1",
+		"refutation: b2 a1, x wins",
+	} {
+		if !strings.Contains(explanation, want) {
+			t.Errorf("explanation missing %#v:\n%s", want, explanation)
+		}
+	}
+
+	if _, err := why(tablebase.Generate(), b, 1, board.Numeric); err == nil {
+		t.Error("why() with an invalid move didn't fail")
+	}
+}
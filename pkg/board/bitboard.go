@@ -13,6 +13,8 @@
 
 package board
 
+import "math/bits"
+
 // BitBoard represents a tic tac toe board where each cell can be one of
 // two states, set or not set.
 type Bitboard struct {
@@ -56,36 +58,68 @@ func (b *Bitboard) Unset(pos Move) {
 // HasWon checks if one of the given lines are completely set in the
 // Bitboard. In a player's bitboard, it checks if the player has won.
 func (b *Bitboard) HasWon() bool {
-	// ways in which a player can win
-	winningLines := [][3]Move{
-		// rows
-		{1, 2, 3},
-		{4, 5, 6},
-		{7, 8, 9},
-
-		// columns
-		{1, 4, 7},
-		{2, 5, 8},
-		{3, 6, 9},
-
-		// diagonals
-		{1, 5, 9},
-		{3, 5, 7},
-	}
+	return len(b.WinningLines()) > 0
+}
 
-checkingForWins:
-	for _, line := range winningLines {
+// WinningLines returns all the lines which are completely set in the
+// Bitboard. In a player's bitboard, these are the lines the player won on.
+func (b *Bitboard) WinningLines() []Line {
+	var won []Line
+	for i, mask := range lineMasks {
 		// check if all three positions are set
-		for i := 0; i < 3; i++ {
-			if !b.Has(line[i]) {
-				continue checkingForWins
-			}
+		if b.uint16&mask == mask {
+			won = append(won, lines[i])
 		}
+	}
+
+	return won
+}
+
+// Threats returns a Bitboard with the empty cells set which would complete
+// a line in the Bitboard, given the Bitboard of the opponent. In a player's
+// bitboard, these are the cells where the player would immediately win.
+func (b *Bitboard) Threats(opponent Bitboard) Bitboard {
+	var threats uint16
+	for _, mask := range lineMasks {
+		// line should have two set cells and an empty third cell
+		if bits.OnesCount16(b.uint16&mask) == 2 && opponent.uint16&mask == 0 {
+			threats |= mask &^ b.uint16
+		}
+	}
+
+	return Bitboard{threats}
+}
 
-		return true
+// OpenLines returns the number of lines which have exactly the given number
+// of cells set in the Bitboard, and none in the Bitboard of the opponent.
+// In a player's bitboard, these are the lines the player can still win on.
+func (b *Bitboard) OpenLines(opponent Bitboard, marks int) int {
+	var count int
+	for _, mask := range lineMasks {
+		if bits.OnesCount16(b.uint16&mask) == marks && opponent.uint16&mask == 0 {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Count returns the number of set positions in the Bitboard.
+func (b *Bitboard) Count() int {
+	return bits.OnesCount16(b.uint16)
+}
+
+// Cells returns the positions which are set in the Bitboard as a slice of
+// Moves.
+func (b *Bitboard) Cells() []Move {
+	var cells []Move
+	for i := Move(1); i <= 9; i++ {
+		if b.Has(i) {
+			cells = append(cells, i)
+		}
 	}
 
-	return false
+	return cells
 }
 
 // buffer converts a given move into a flag buffer. A flag buffer is a
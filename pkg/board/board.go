@@ -24,8 +24,11 @@ type Board struct {
 	o Bitboard
 
 	// metadata
-	moveNum int   // current move number
-	state   State // state of the game
+	moveNum  int   // current move number
+	state    State // state of the game
+	flipTurn bool  // turn is flipped relative to move number
+
+	hash uint64 // zobrist hash of the position
 }
 
 // Move represents a move on the Board. The numbers 1-9 represent the 9
@@ -62,6 +65,24 @@ func (b Board) String() string {
 	return s
 }
 
+// PositionString converts a Board to it's position string, which is the
+// format accepted by New.
+func (b Board) PositionString() string {
+	var s string
+	for i := Move(1); i <= 9; i++ {
+		switch {
+		case b.x.Has(i):
+			s += "x"
+		case b.o.Has(i):
+			s += "o"
+		default:
+			s += "."
+		}
+	}
+
+	return s
+}
+
 // InvalidMove represents an invalid move provided to Play.
 type InvalidMove struct {
 	move Move
@@ -79,6 +100,9 @@ func (b *Board) Play(move Move) error {
 		return InvalidMove{move}
 	}
 
+	// update hash incrementally
+	b.hash ^= zobristMark[b.Turn()][move] ^ zobristTurn
+
 	if b.XsTurn() {
 		b.x.Set(move)
 	} else {
@@ -92,6 +116,44 @@ func (b *Board) Play(move Move) error {
 	return nil
 }
 
+// InvalidUndo represents an invalid move provided to Undo.
+type InvalidUndo struct {
+	move Move
+}
+
+func (e InvalidUndo) Error() string {
+	return fmt.Sprintf("undo: invalid move %d", e.move)
+}
+
+// Undo takes back the given move from it's Board, and updates the position
+// and state accordingly. The move should have been made by the player who
+// made the last move, but it doesn't need to be the last move itself, so
+// Undo can be used to find all the positions preceding a position.
+func (b *Board) Undo(move Move) error {
+	// move should be marked by the last player
+	switch {
+	case move > 9 || move < 1:
+		return InvalidUndo{move}
+	case b.XsTurn() && !b.o.Has(move), !b.XsTurn() && !b.x.Has(move):
+		return InvalidUndo{move}
+	}
+
+	// update hash incrementally
+	b.hash ^= zobristMark[b.Turn().Other()][move] ^ zobristTurn
+
+	if b.XsTurn() {
+		b.o.Unset(move)
+	} else {
+		b.x.Unset(move)
+	}
+
+	// decrease move count
+	b.moveNum--
+
+	b.updateState()
+	return nil
+}
+
 // updateState checks for wins or draws in the Board and updates the state
 // accordingly.
 func (b *Board) updateState() {
@@ -112,6 +174,68 @@ func (b *Board) updateState() {
 	}
 }
 
+// WinningLines returns the lines which were completed by the winner of the
+// game. It returns nil if the game has not been won.
+func (b *Board) WinningLines() []Line {
+	switch b.state {
+	case PlayerXWon:
+		return b.x.WinningLines()
+	case PlayerOWon:
+		return b.o.WinningLines()
+	default:
+		return nil
+	}
+}
+
+// IsLegal checks if the Board represents a position which can be reached
+// in a game of tic tac toe. Unlike IsValidPosition, it also checks that at
+// most one player has won, that the winner made the last move, and that
+// all the winning lines were completed by that single move.
+func (b *Board) IsLegal() bool {
+	xLines := b.x.WinningLines()
+	oLines := b.o.WinningLines()
+
+	var won []Line
+	switch {
+	case len(xLines) > 0 && len(oLines) > 0:
+		// both players can't win
+		return false
+	case len(xLines) > 0:
+		// x must have made the last move
+		if b.XsTurn() {
+			return false
+		}
+
+		won = xLines
+	case len(oLines) > 0:
+		// o must have made the last move
+		if !b.XsTurn() {
+			return false
+		}
+
+		won = oLines
+	default:
+		return true
+	}
+
+	// the last move must be a part of every winning line
+	for cell := Move(1); cell <= 9; cell++ {
+		common := true
+		for _, line := range won {
+			if !line.Has(cell) {
+				common = false
+				break
+			}
+		}
+
+		if common {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ValidMoves calculates the valid moves in current position and returns
 // them as a slice of Moves.
 func (b *Board) ValidMoves() []Move {
@@ -132,7 +256,7 @@ func (b *Board) ValidMoves() []Move {
 // XsTurn checks whether it is x's turn to play and returns a bool
 // accordingly.
 func (b *Board) XsTurn() bool {
-	return b.moveNum%2 == 0
+	return (b.moveNum%2 == 0) != b.flipTurn
 }
 
 // IsValidMove checks if the given move is valid on it's Board.
@@ -0,0 +1,84 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+// zobrist keys used for hashing Boards. A Board's hash is the xor of the
+// keys of all the marks on it, along with zobristTurn if it's o's turn.
+var (
+	zobristMark [2][10]uint64 // keys for each player's mark on each cell
+	zobristTurn uint64        // key for o's turn
+)
+
+func init() {
+	// the keys are generated using a fixed seed so that hashes are the
+	// same across different runs
+	seed := uint64(0x9e3779b97f4a7c15)
+	for player := range zobristMark {
+		for cell := 1; cell <= 9; cell++ {
+			zobristMark[player][cell] = splitmix64(&seed)
+		}
+	}
+
+	zobristTurn = splitmix64(&seed)
+}
+
+// splitmix64 generates the next pseudo-random number from the given state
+// using the SplitMix64 algorithm.
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Hash returns the 64-bit zobrist hash of the Board, which depends on the
+// marks on the Board and whose turn it is. The hash is updated
+// incrementally by Play and Undo, so it is cheap to fetch.
+func (b *Board) Hash() uint64 {
+	return b.hash
+}
+
+// SymmetricHash returns a hash of the Board which is the same for all the
+// Boards which are equivalent under the symmetries of the tic tac toe
+// board, i.e, rotations and reflections.
+func (b *Board) SymmetricHash() uint64 {
+	hash := b.hash
+	for _, s := range symmetries[1:] {
+		if transformed := b.Transform(s); transformed.hash < hash {
+			hash = transformed.hash
+		}
+	}
+
+	return hash
+}
+
+// computeHash calculates the hash of the Board from scratch.
+func (b *Board) computeHash() uint64 {
+	var hash uint64
+	for cell := Move(1); cell <= 9; cell++ {
+		switch {
+		case b.x.Has(cell):
+			hash ^= zobristMark[PlayerX][cell]
+		case b.o.Has(cell):
+			hash ^= zobristMark[PlayerO][cell]
+		}
+	}
+
+	if !b.XsTurn() {
+		hash ^= zobristTurn
+	}
+
+	return hash
+}
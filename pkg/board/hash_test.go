@@ -0,0 +1,63 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+import "testing"
+
+func TestHashUnique(t *testing.T) {
+	hashes := make(map[uint64]Board)
+	for _, b := range reachablePositions() {
+		if other, found := hashes[b.Hash()]; found {
+			t.Fatalf("%s and %s share the hash %#x", b.PositionString(), other.PositionString(), b.Hash())
+		}
+
+		hashes[b.Hash()] = b
+	}
+}
+
+func TestHashIncremental(t *testing.T) {
+	for _, b := range reachablePositions() {
+		if b.Hash() != b.computeHash() {
+			t.Fatalf("%s: hash %#x, computed %#x", b.PositionString(), b.Hash(), b.computeHash())
+		}
+
+		for _, move := range b.ValidMoves() {
+			after := b
+			after.Play(move)
+			if after.Hash() != after.computeHash() {
+				t.Fatalf("%s: hash %#x after playing %d, computed %#x", b.PositionString(), after.Hash(), move, after.computeHash())
+			}
+
+			after.Undo(move)
+			if after.Hash() != b.Hash() {
+				t.Fatalf("%s: hash %#x after undoing %d, want %#x", b.PositionString(), after.Hash(), move, b.Hash())
+			}
+		}
+	}
+}
+
+func TestSymmetricHash(t *testing.T) {
+	if n := len(Symmetries()); n != 8 {
+		t.Fatalf("%d symmetries, want 8", n)
+	}
+
+	for _, b := range reachablePositions() {
+		for _, s := range Symmetries() {
+			transformed := b.Transform(s)
+			if transformed.SymmetricHash() != b.SymmetricHash() {
+				t.Fatalf("%s: symmetric hash differs under symmetry %d", b.PositionString(), s)
+			}
+		}
+	}
+}
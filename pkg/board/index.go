@@ -0,0 +1,148 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+import "sync"
+
+// TernarySize is the number of different ternary indices, i.e, the number
+// of ways in which the cells of a Board can be filled.
+const TernarySize = 19683 // 3^9
+
+// Ternary converts the position of a Board into it's ternary index, where
+// each cell is a ternary digit which is 0 for an empty cell, 1 for a mark
+// by player x, and 2 for a mark by player o. Cell 1 is the least
+// significant digit. The index doesn't store whose turn it is, so boards
+// created with NewSetup may share their index with other boards.
+func (b Board) Ternary() uint32 {
+	var index uint32
+	for cell := Move(9); cell >= 1; cell-- {
+		index *= 3
+		switch {
+		case b.x.Has(cell):
+			index += 1
+		case b.o.Has(cell):
+			index += 2
+		}
+	}
+
+	return index
+}
+
+// FromTernary creates a new Board from the given ternary index. It returns
+// a PositionError if the index doesn't represent a valid position.
+func FromTernary(index uint32) (Board, error) {
+	pos := make([]byte, 9)
+	for i := range pos {
+		pos[i] = ".xo"[index%3]
+		index /= 3
+	}
+
+	if index != 0 {
+		// index is larger than TernarySize
+		return Board{}, PositionError{string(pos)}
+	}
+
+	return New(string(pos))
+}
+
+// ranking stores the data needed to rank and unrank positions. It is
+// initialized lazily by the first call to a ranking function.
+var ranking struct {
+	once sync.Once
+
+	positions []uint32   // ternary indices of legal positions, by rank
+	ranks     []uint32   // ranks of ternary indices, if legal
+	plyStart  [11]uint32 // rank of first position of each ply
+}
+
+// initRanking enumerates all the legal positions and ranks them, first by
+// their move number and then by their ternary index.
+func initRanking() {
+	ranking.ranks = make([]uint32, TernarySize)
+
+	var plies [10][]uint32
+	for index := uint32(0); index < TernarySize; index++ {
+		ranking.ranks[index] = InvalidRank
+		if b, err := FromTernary(index); err == nil && b.IsLegal() {
+			plies[b.moveNum] = append(plies[b.moveNum], index)
+		}
+	}
+
+	for ply, indices := range plies {
+		ranking.plyStart[ply] = uint32(len(ranking.positions))
+		ranking.positions = append(ranking.positions, indices...)
+	}
+
+	ranking.plyStart[10] = uint32(len(ranking.positions))
+	for rank, index := range ranking.positions {
+		ranking.ranks[index] = uint32(rank)
+	}
+}
+
+// InvalidRank is the rank of positions which are not legal.
+const InvalidRank = ^uint32(0)
+
+// Positions returns the number of legal positions, i.e, the positions which
+// can be reached in a game of tic tac toe. Ranks go from 0 to Positions()-1.
+func Positions() uint32 {
+	ranking.once.Do(initRanking)
+	return uint32(len(ranking.positions))
+}
+
+// PlyPositions returns the number of legal positions with the given move
+// number.
+func PlyPositions(ply int) uint32 {
+	ranking.once.Do(initRanking)
+	return ranking.plyStart[ply+1] - ranking.plyStart[ply]
+}
+
+// Rank converts the given Board into it's rank, which is a unique number
+// from 0 to Positions()-1 for each legal position. Positions are ranked
+// first by their move number, and then by their ternary index, so the ranks
+// of positions with the same move number are consecutive. Whose turn it is
+// is not considered. It returns InvalidRank if the position is not legal.
+func Rank(b Board) uint32 {
+	ranking.once.Do(initRanking)
+	return ranking.ranks[b.Ternary()]
+}
+
+// Unrank converts the given rank back into the Board it represents. It
+// panics if the rank is not less than Positions().
+func Unrank(rank uint32) Board {
+	ranking.once.Do(initRanking)
+	b, _ := FromTernary(ranking.positions[rank])
+	return b
+}
+
+// RankPly is like Rank, but the rank is relative to the positions with the
+// same move number as the given Board, and goes from 0 to PlyPositions()-1.
+func RankPly(b Board) uint32 {
+	rank := Rank(b)
+	if rank == InvalidRank {
+		return InvalidRank
+	}
+
+	return rank - ranking.plyStart[b.moveNum]
+}
+
+// UnrankPly converts the given rank relative to the positions with the given
+// move number back into the Board it represents. It panics if the rank is
+// not less than PlyPositions(ply).
+func UnrankPly(ply int, rank uint32) Board {
+	if rank >= PlyPositions(ply) {
+		panic("board: rank out of range")
+	}
+
+	return Unrank(ranking.plyStart[ply] + rank)
+}
@@ -0,0 +1,114 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board_test
+
+import (
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func TestPositions(t *testing.T) {
+	if n := board.Positions(); n != 5478 {
+		t.Errorf("Positions() = %d, want 5478", n)
+	}
+
+	var total uint32
+	for ply := 0; ply <= 9; ply++ {
+		total += board.PlyPositions(ply)
+	}
+
+	if total != board.Positions() {
+		t.Errorf("sum of PlyPositions() = %d, want %d", total, board.Positions())
+	}
+}
+
+func TestRank(t *testing.T) {
+	positions := tablebase.Generate().Positions()
+	if len(positions) != int(board.Positions()) {
+		t.Fatalf("tablebase has %d positions, want %d", len(positions), board.Positions())
+	}
+
+	ranked := make(map[uint32]bool)
+	for _, data := range positions {
+		b := data.Position()
+
+		rank := board.Rank(b)
+		switch {
+		case rank >= board.Positions():
+			t.Fatalf("%s: rank %d out of range", b.PositionString(), rank)
+		case ranked[rank]:
+			t.Fatalf("%s: rank %d is not unique", b.PositionString(), rank)
+		case board.Unrank(rank) != b:
+			t.Fatalf("%s: Unrank(%d) = %s", b.PositionString(), rank, board.Unrank(rank).PositionString())
+		}
+
+		ranked[rank] = true
+	}
+}
+
+func TestRankPly(t *testing.T) {
+	for _, data := range tablebase.Generate().Positions() {
+		b := data.Position()
+		ply := b.MoveNumber()
+
+		rank := board.RankPly(b)
+		switch {
+		case rank >= board.PlyPositions(ply):
+			t.Fatalf("%s: ply rank %d out of range", b.PositionString(), rank)
+		case board.UnrankPly(ply, rank) != b:
+			t.Fatalf("%s: UnrankPly(%d, %d) = %s", b.PositionString(), ply, rank, board.UnrankPly(ply, rank).PositionString())
+		}
+	}
+}
+
+func TestRankIllegal(t *testing.T) {
+	// both players have won
+	b, err := board.NewSetup("xxxooo...", board.PlayerX)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rank := board.Rank(b); rank != board.InvalidRank {
+		t.Errorf("Rank() = %d, want InvalidRank", rank)
+	}
+
+	if rank := board.RankPly(b); rank != board.InvalidRank {
+		t.Errorf("RankPly() = %d, want InvalidRank", rank)
+	}
+}
+
+func TestUnrankOutOfRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		unrank func()
+	}{
+		{"Unrank", func() { board.Unrank(board.Positions()) }},
+		{"UnrankPly", func() { board.UnrankPly(0, 1) }},
+		{"UnrankPly", func() { board.UnrankPly(9, board.PlyPositions(9)) }},
+	}
+
+	for _, test := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s didn't panic on an out of range rank", test.name)
+				}
+			}()
+
+			test.unrank()
+		}()
+	}
+}
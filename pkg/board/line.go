@@ -0,0 +1,61 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+import "fmt"
+
+// Line represents a line of three cells on the Board. A player who marks
+// all the cells of a line wins the game.
+type Line [3]Move
+
+// lines contains all the ways in which a player can win.
+var lines = [...]Line{
+	// rows
+	{1, 2, 3},
+	{4, 5, 6},
+	{7, 8, 9},
+
+	// columns
+	{1, 4, 7},
+	{2, 5, 8},
+	{3, 6, 9},
+
+	// diagonals
+	{1, 5, 9},
+	{3, 5, 7},
+}
+
+// lineMasks contains the Bitboard masks of all the lines, in the same order
+// as lines. Checking a line with it's mask is faster than checking each of
+// it's cells one by one.
+var lineMasks = func() (masks [len(lines)]uint16) {
+	for i, line := range lines {
+		for _, cell := range line {
+			masks[i] |= buffer(cell)
+		}
+	}
+
+	return masks
+}()
+
+// String converts a Line to it's string representation, which consists of
+// it's cells separated by dashes, like 3-5-7.
+func (l Line) String() string {
+	return fmt.Sprintf("%d-%d-%d", l[0], l[1], l[2])
+}
+
+// Has checks if the given cell is a part of the Line.
+func (l Line) Has(cell Move) bool {
+	return l[0] == cell || l[1] == cell || l[2] == cell
+}
@@ -0,0 +1,201 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Notation represents a way of writing moves and positions as strings.
+// Every notation can be parsed by ParseMove and New, so it only decides
+// how moves and positions are formatted.
+type Notation int
+
+// Constants representing the supported notations.
+const (
+	// Numeric notation represents moves by the numbers 1-9, from left to
+	// right and top to bottom, and positions by 9-character strings.
+	Numeric Notation = iota
+
+	// Algebraic notation represents moves by their column, from a to c
+	// left to right, and their row, from 1 to 3 bottom to top, like the
+	// squares of a chess board. For example, b2 is the centre. Positions
+	// are represented by their rows from top to bottom, separated by
+	// slashes, like xo./.x./..o.
+	Algebraic
+
+	// RowColumn notation represents moves by their row and column, from 1
+	// to 3 top to bottom and left to right, separated by a comma. For
+	// example, 2,2 is the centre. Positions are represented like in
+	// Algebraic notation.
+	RowColumn
+)
+
+// ParseNotation parses the name of a notation. It returns false as the
+// second argument if there is no notation with the given name.
+func ParseNotation(name string) (Notation, bool) {
+	switch name {
+	case "numeric":
+		return Numeric, true
+	case "algebraic":
+		return Algebraic, true
+	case "rowcol":
+		return RowColumn, true
+	default:
+		return 0, false
+	}
+}
+
+// String converts a Notation into it's name, which is accepted by
+// ParseNotation.
+func (n Notation) String() string {
+	switch n {
+	case Numeric:
+		return "numeric"
+	case Algebraic:
+		return "algebraic"
+	case RowColumn:
+		return "rowcol"
+	default:
+		return "invalid notation"
+	}
+}
+
+// FormatMove converts the given move into it's string representation in
+// the Notation.
+func (n Notation) FormatMove(m Move) string {
+	row, column := int(m-1)/3, int(m-1)%3
+
+	switch n {
+	case Algebraic:
+		return fmt.Sprintf("%c%d", 'a'+column, 3-row)
+	case RowColumn:
+		return fmt.Sprintf("%d,%d", row+1, column+1)
+	default:
+		return fmt.Sprint(int(m))
+	}
+}
+
+// FormatMoves converts the given moves into a space separated list in the
+// Notation.
+func (n Notation) FormatMoves(moves []Move) string {
+	s := make([]string, len(moves))
+	for i, move := range moves {
+		s[i] = n.FormatMove(move)
+	}
+
+	return strings.Join(s, " ")
+}
+
+// FormatLine converts the given line into it's cells in the Notation,
+// separated by dashes, like 3-5-7.
+func (n Notation) FormatLine(l Line) string {
+	return n.FormatMove(l[0]) + "-" + n.FormatMove(l[1]) + "-" + n.FormatMove(l[2])
+}
+
+// FormatPosition converts the position of the given Board into it's string
+// representation in the Notation, which is accepted by New.
+func (n Notation) FormatPosition(b Board) string {
+	pos := b.PositionString()
+	if n == Numeric {
+		return pos
+	}
+
+	return pos[0:3] + "/" + pos[3:6] + "/" + pos[6:9]
+}
+
+// MoveError is the error reported when an invalid move string is provided
+// to ParseMove.
+type MoveError struct {
+	moveString string
+}
+
+func (e MoveError) Error() string {
+	return fmt.Sprintf("board: invalid move string %#v", e.moveString)
+}
+
+// ParseMove parses a move written in any of the supported notations. It
+// returns a MoveError if the move string is invalid. Note that the move
+// may still be invalid in a particular position.
+func ParseMove(s string) (Move, error) {
+	switch {
+	// numeric notation, like 5
+	case len(s) == 1 && s[0] >= '1' && s[0] <= '9':
+		return Move(s[0] - '0'), nil
+
+	// algebraic notation, like b2
+	case len(s) == 2 && isColumn(s[0]) && s[1] >= '1' && s[1] <= '3':
+		column := int(s[0]|0x20) - 'a' // lower case the column
+		row := int('3' - s[1])
+		return Move(row*3 + column + 1), nil
+
+	// row column notation, like 2,2
+	case len(s) == 3 && s[1] == ',' && s[0] >= '1' && s[0] <= '3' && s[2] >= '1' && s[2] <= '3':
+		row, column := int(s[0]-'1'), int(s[2]-'1')
+		return Move(row*3 + column + 1), nil
+
+	default:
+		return 0, MoveError{s}
+	}
+}
+
+// isColumn checks if the given character is the column of an algebraic
+// move, in either case.
+func isColumn(c byte) bool {
+	return (c >= 'a' && c <= 'c') || (c >= 'A' && c <= 'C')
+}
+
+// normalizePosition converts a position string written in any of the
+// supported notations into the 9-character form with the symbols x, o, and
+// ., which is used internally. Marks may be written in upper case, empty
+// cells may be written as - or _, and the rows may be separated by
+// slashes. It returns false as the second argument if the position string
+// is not written in a supported notation.
+func normalizePosition(pos string) (string, bool) {
+	if strings.Contains(pos, "/") {
+		rows := strings.Split(pos, "/")
+		if len(rows) != 3 {
+			return "", false
+		}
+
+		for _, row := range rows {
+			if len(row) != 3 {
+				return "", false
+			}
+		}
+
+		pos = strings.Join(rows, "")
+	}
+
+	normalized := strings.Map(func(r rune) rune {
+		switch r {
+		case 'x', 'X':
+			return 'x'
+		case 'o', 'O':
+			return 'o'
+		case '.', '-', '_':
+			return '.'
+		default:
+			// invalid symbol
+			return -1
+		}
+	}, pos)
+
+	if len(pos) != 9 || len(normalized) != 9 {
+		return "", false
+	}
+
+	return normalized, true
+}
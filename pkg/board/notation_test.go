@@ -0,0 +1,180 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+import "testing"
+
+func TestParseMove(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Move
+	}{
+		{"1", 1},
+		{"9", 9},
+		{"a1", 7},
+		{"A1", 7},
+		{"b2", 5},
+		{"C3", 3},
+		{"c1", 9},
+		{"a3", 1},
+		{"2,2", 5},
+		{"1,3", 3},
+		{"3,1", 7},
+	}
+
+	for _, test := range tests {
+		if got, err := ParseMove(test.s); err != nil || got != test.want {
+			t.Errorf("ParseMove(%#v) = %d, %v, want %d", test.s, got, err, test.want)
+		}
+	}
+}
+
+func TestParseMoveInvalid(t *testing.T) {
+	for _, s := range []string{
+		"", "0", "10", "d1", "a0", "a4", "b22", "4,1", "1,4", "0,1", "2,", ",2", "2;2", "2,2,", "x",
+	} {
+		if move, err := ParseMove(s); err == nil {
+			t.Errorf("ParseMove(%#v) = %d, want error", s, move)
+		} else if _, ok := err.(MoveError); !ok {
+			t.Errorf("ParseMove(%#v) returned %T, want MoveError", s, err)
+		}
+	}
+}
+
+func TestFormatMove(t *testing.T) {
+	tests := []struct {
+		notation Notation
+		want     string
+	}{
+		{Numeric, "1 2 3 4 5 6 7 8 9"},
+		{Algebraic, "a3 b3 c3 a2 b2 c2 a1 b1 c1"},
+		{RowColumn, "1,1 1,2 1,3 2,1 2,2 2,3 3,1 3,2 3,3"},
+	}
+
+	moves := []Move{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	for _, test := range tests {
+		if got := test.notation.FormatMoves(moves); got != test.want {
+			t.Errorf("%s: FormatMoves() = %#v, want %#v", test.notation, got, test.want)
+		}
+
+		// every formatted move parses back into itself
+		for _, move := range moves {
+			s := test.notation.FormatMove(move)
+			if parsed, err := ParseMove(s); err != nil || parsed != move {
+				t.Errorf("%s: ParseMove(%#v) = %d, %v, want %d", test.notation, s, parsed, err, move)
+			}
+		}
+	}
+}
+
+func TestFormatLine(t *testing.T) {
+	tests := []struct {
+		notation Notation
+		want     string
+	}{
+		{Numeric, "3-5-7"},
+		{Algebraic, "c3-b2-a1"},
+		{RowColumn, "1,3-2,2-3,1"},
+	}
+
+	for _, test := range tests {
+		if got := test.notation.FormatLine(Line{3, 5, 7}); got != test.want {
+			t.Errorf("%s: FormatLine() = %#v, want %#v", test.notation, got, test.want)
+		}
+	}
+
+	if got := Numeric.FormatLine(Line{1, 2, 3}); got != (Line{1, 2, 3}).String() {
+		t.Errorf("numeric FormatLine() = %#v, want Line.String()", got)
+	}
+}
+
+func TestParseNotation(t *testing.T) {
+	for _, notation := range []Notation{Numeric, Algebraic, RowColumn} {
+		if parsed, found := ParseNotation(notation.String()); !found || parsed != notation {
+			t.Errorf("ParseNotation(%#v) = %s, %v", notation.String(), parsed, found)
+		}
+	}
+
+	if _, found := ParseNotation("chess"); found {
+		t.Error("ParseNotation(\"chess\") found a notation")
+	}
+}
+
+func TestNewNotations(t *testing.T) {
+	want := mustNew("xo.x..o..")
+	for _, pos := range []string{
+		"XO.X..O..",
+		"xo-x--o--",
+		"xo_x__o__",
+		"Xo-/x_./O__",
+		"xo./x../o..",
+	} {
+		if b, err := New(pos); err != nil || b != want {
+			t.Errorf("New(%#v) = %s, %v, want %s", pos, b.PositionString(), err, want.PositionString())
+		}
+	}
+
+	for _, pos := range []string{
+		"xo./x..",         // too few rows
+		"xo./x../o../...", // too many rows
+		"xo./x.../o.",     // wrong row lengths
+		"xo.x/../o..",     // wrong row lengths
+		"xo./x../o..//",   // empty rows
+		"xo.x..o.",        // too short
+		"xo.x..o...",      // too long
+		"xo.x..o.?",       // invalid symbol
+		"xo.x..o.é",       // invalid symbol
+		"xo./x../o../",    // trailing slash
+		"/xo./x../o..",    // leading slash
+		"xxx/x../o..",     // too many x marks
+	} {
+		if _, err := New(pos); err == nil {
+			t.Errorf("New(%#v) didn't fail", pos)
+		}
+	}
+}
+
+func TestNewSetupNotations(t *testing.T) {
+	b, err := NewSetup("X__/_O_/___", PlayerX)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b.PositionString() != "x...o...." || b.Turn() != PlayerX {
+		t.Errorf("NewSetup() = %s with %s to move", b.PositionString(), b.Turn())
+	}
+}
+
+func TestFormatPosition(t *testing.T) {
+	b := mustNew("xo.x..o..")
+	tests := []struct {
+		notation Notation
+		want     string
+	}{
+		{Numeric, "xo.x..o.."},
+		{Algebraic, "xo./x../o.."},
+		{RowColumn, "xo./x../o.."},
+	}
+
+	for _, test := range tests {
+		got := test.notation.FormatPosition(b)
+		if got != test.want {
+			t.Errorf("%s: FormatPosition() = %#v, want %#v", test.notation, got, test.want)
+		}
+
+		if parsed, err := New(got); err != nil || parsed != b {
+			t.Errorf("%s: New(%#v) = %s, %v", test.notation, got, parsed.PositionString(), err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+// Player represents one of the two players of a tic tac toe game.
+type Player int
+
+// Constants representing the two players.
+const (
+	PlayerX Player = iota
+	PlayerO
+)
+
+// String converts a Player into it's string representation, which is the
+// symbol of it's mark.
+func (p Player) String() string {
+	switch p {
+	case PlayerX:
+		return "x"
+	case PlayerO:
+		return "o"
+	default:
+		return "invalid player"
+	}
+}
+
+// Other returns the opponent of the given Player.
+func (p Player) Other() Player {
+	if p == PlayerX {
+		return PlayerO
+	}
+
+	return PlayerX
+}
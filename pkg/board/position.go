@@ -21,11 +21,14 @@ import (
 // IsValidPosition verifies whether the given string is a valid tic tac toe
 // position string. Note that this is just a simple check, and it
 // classifies positions with multiple winners as valid. The final
-// verification is whether the position is present in the tablebase or not.
+// verification is whether the position is present in the tablebase or not,
+// or alternatively, IsLegalPosition. The position string may be written in
+// any of the notations accepted by New.
 func IsValidPosition(pos string) bool {
-	// the position string's length should be 9, and it should be
-	// entirely composed of x, o, and .
-	if len(pos) != 9 || len(strings.Trim(pos, "xo.")) != 0 {
+	// the position string should have 9 cells, each of which is
+	// one of x, o, and . in some notation
+	pos, ok := normalizePosition(pos)
+	if !ok {
 		return false
 	}
 
@@ -41,6 +44,14 @@ func IsValidPosition(pos string) bool {
 	return true
 }
 
+// IsLegalPosition is a strict version of IsValidPosition, which also
+// verifies that the position can be reached in a game of tic tac toe, for
+// example by rejecting positions with multiple winners.
+func IsLegalPosition(pos string) bool {
+	b, err := New(pos)
+	return err == nil && b.IsLegal()
+}
+
 // PositionError is the error reported when an invalid tic tac toe position
 // string is provided to some methods.
 type PositionError struct {
@@ -57,12 +68,42 @@ func (e PositionError) Error() string {
 // A tic tac toe position string is a string of length 9, where each
 // character represents a cell on the board. The symbols x, o, and .
 // represent a mark by player x, a mark by player o, and an empty cell
-// respectively.
+// respectively. The symbols X and O in upper case, and - or _ for empty
+// cells, are also accepted, and the rows may be separated by slashes, like
+// xo./.x./..o.
 func New(pos string) (Board, error) {
 	if !IsValidPosition(pos) {
 		return Board{}, PositionError{pos}
 	}
 
+	normalized, _ := normalizePosition(pos)
+	return newBoard(normalized), nil
+}
+
+// NewSetup creates a new Board with the given position and the given
+// player to move. Unlike New, the number of marks of each player is not
+// checked, so it can be used for custom setups like handicap starts or
+// puzzle positions with extra marks. It returns a PositionError if the
+// given position string contains invalid symbols. Like New, it accepts
+// position strings in any notation.
+func NewSetup(pos string, turn Player) (Board, error) {
+	normalized, ok := normalizePosition(pos)
+	if !ok {
+		return Board{}, PositionError{pos}
+	}
+
+	b := newBoard(normalized)
+	if b.Turn() != turn {
+		b.flipTurn = true
+		b.hash ^= zobristTurn
+	}
+
+	return b, nil
+}
+
+// newBoard creates a new Board from the given position string, which must
+// only contain valid symbols.
+func newBoard(pos string) Board {
 	var x Bitboard
 	var o Bitboard
 
@@ -93,8 +134,9 @@ func New(pos string) (Board, error) {
 		moveNum: moves,
 	}
 
-	// update state of board
+	// update state and hash of board
 	b.updateState()
+	b.hash = b.computeHash()
 
-	return b, nil
+	return b
 }
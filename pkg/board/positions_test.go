@@ -0,0 +1,51 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+// reachablePositions returns every position which can be reached from the
+// starting position, each exactly once.
+func reachablePositions() []Board {
+	seen := make(map[Board]bool)
+
+	var positions []Board
+	var walk func(b Board)
+	walk = func(b Board) {
+		if seen[b] {
+			return
+		}
+
+		seen[b] = true
+		positions = append(positions, b)
+
+		for _, move := range b.ValidMoves() {
+			next := b
+			next.Play(move)
+			walk(next)
+		}
+	}
+
+	walk(Board{})
+	return positions
+}
+
+// mustNew creates a new Board with the given position, and panics if the
+// position string is invalid.
+func mustNew(pos string) Board {
+	b, err := New(pos)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
@@ -0,0 +1,100 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+// Symmetry represents one of the 8 symmetries of the tic tac toe board, which
+// are rotations and reflections that don't change the game.
+type Symmetry int
+
+// Constants representing the symmetries of the board.
+const (
+	Identity         Symmetry = iota
+	Rotate90                  // rotate clockwise by 90 degrees
+	Rotate180                 // rotate by 180 degrees
+	Rotate270                 // rotate clockwise by 270 degrees
+	FlipHorizontal            // mirror the columns
+	FlipVertical              // mirror the rows
+	FlipDiagonal              // mirror along the 1-5-9 diagonal
+	FlipAntiDiagonal          // mirror along the 3-5-7 diagonal
+)
+
+// symmetries contains all the symmetries, starting with Identity.
+var symmetries = []Symmetry{
+	Identity, Rotate90, Rotate180, Rotate270,
+	FlipHorizontal, FlipVertical, FlipDiagonal, FlipAntiDiagonal,
+}
+
+// Symmetries returns all the symmetries of the board, starting with
+// Identity.
+func Symmetries() []Symmetry {
+	return append([]Symmetry(nil), symmetries...)
+}
+
+// Apply returns the cell which the given cell is moved to by the Symmetry.
+func (s Symmetry) Apply(cell Move) Move {
+	row, col := int(cell-1)/3, int(cell-1)%3
+
+	switch s {
+	case Rotate90:
+		row, col = col, 2-row
+	case Rotate180:
+		row, col = 2-row, 2-col
+	case Rotate270:
+		row, col = 2-col, row
+	case FlipHorizontal:
+		col = 2 - col
+	case FlipVertical:
+		row = 2 - row
+	case FlipDiagonal:
+		row, col = col, row
+	case FlipAntiDiagonal:
+		row, col = 2-col, 2-row
+	}
+
+	return Move(row*3 + col + 1)
+}
+
+// Transform returns a copy of the Board with the given Symmetry applied
+// to it's position.
+func (b *Board) Transform(s Symmetry) Board {
+	transformed := *b
+	transformed.x = Bitboard{}
+	transformed.o = Bitboard{}
+
+	for cell := Move(1); cell <= 9; cell++ {
+		switch {
+		case b.x.Has(cell):
+			transformed.x.Set(s.Apply(cell))
+		case b.o.Has(cell):
+			transformed.o.Set(s.Apply(cell))
+		}
+	}
+
+	transformed.hash = transformed.computeHash()
+	return transformed
+}
+
+// Canonical returns the canonical form of the Board, which is the same for
+// all the Boards which are equivalent under the symmetries of the board.
+// It is the symmetric Board with the smallest ternary index.
+func (b *Board) Canonical() Board {
+	canonical := *b
+	for _, s := range symmetries[1:] {
+		if transformed := b.Transform(s); transformed.Ternary() < canonical.Ternary() {
+			canonical = transformed
+		}
+	}
+
+	return canonical
+}
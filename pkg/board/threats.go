@@ -0,0 +1,153 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+// Turn returns the Player whose turn it is to play.
+func (b *Board) Turn() Player {
+	if b.XsTurn() {
+		return PlayerX
+	}
+
+	return PlayerO
+}
+
+// Threats returns the cells where the given player would immediately
+// complete a line if it was their turn. It returns nil if the game has
+// finished.
+func (b *Board) Threats(p Player) []Move {
+	if b.state != Unfinished {
+		return nil
+	}
+
+	own, opponent := b.bitboards(p)
+	threats := own.Threats(opponent)
+	return threats.Cells()
+}
+
+// HasFork checks if the given player has a double threat, i.e, two or more
+// cells where they would complete a line. The opponent can't block both of
+// them with a single move.
+func (b *Board) HasFork(p Player) bool {
+	return len(b.Threats(p)) >= 2
+}
+
+// ForkingMoves returns the moves which create a fork for the given player
+// if it was their turn. Moves which immediately win are not included.
+func (b *Board) ForkingMoves(p Player) []Move {
+	if b.state != Unfinished {
+		return nil
+	}
+
+	own, opponent := b.bitboards(p)
+
+	var forks []Move
+	for move := Move(1); move <= 9; move++ {
+		if !b.IsValidMove(move) {
+			continue
+		}
+
+		after := own
+		after.Set(move)
+
+		threats := after.Threats(opponent)
+		if !after.HasWon() && threats.Count() >= 2 {
+			forks = append(forks, move)
+		}
+	}
+
+	return forks
+}
+
+// BlockingMoves returns the moves for the given player which prevent all
+// of the opponent's forks. A move blocks if after it the opponent has no
+// move which creates a fork, or if it creates a threat which forces the
+// opponent to defend on a cell which doesn't create a fork. Moves which win
+// outright always block, while moves which leave the opponent an immediate
+// win never do. It returns nil if the opponent can't create a fork.
+func (b *Board) BlockingMoves(p Player) []Move {
+	if len(b.ForkingMoves(p.Other())) == 0 {
+		return nil
+	}
+
+	var blocks []Move
+	for move := Move(1); move <= 9; move++ {
+		if !b.IsValidMove(move) {
+			continue
+		}
+
+		after := b.withMark(p, move)
+		if len(after.Threats(p.Other())) > 0 {
+			// opponent wins instead of defending or forking
+			continue
+		}
+
+		switch threats := after.Threats(p); len(threats) {
+		case 0:
+			// opponent is free to play anywhere
+			if len(after.ForkingMoves(p.Other())) == 0 {
+				blocks = append(blocks, move)
+			}
+		case 1:
+			// opponent is forced to defend the threat
+			defended := after.withMark(p.Other(), threats[0])
+			if !defended.HasFork(p.Other()) {
+				blocks = append(blocks, move)
+			}
+		default:
+			// player has a fork of their own
+			blocks = append(blocks, move)
+		}
+	}
+
+	return blocks
+}
+
+// Marks returns the Bitboard of the cells marked by the given player.
+func (b *Board) Marks(p Player) Bitboard {
+	own, _ := b.bitboards(p)
+	return own
+}
+
+// OpenLines returns the number of lines which contain exactly the given
+// number of marks by the given player, and no marks by it's opponent.
+func (b *Board) OpenLines(p Player, marks int) int {
+	own, opponent := b.bitboards(p)
+	return own.OpenLines(opponent, marks)
+}
+
+// withMark returns a copy of the Board with the given cell marked by the
+// given player, irrespective of whose turn it is. The state of the copy is
+// updated, so a mark which completes a line finishes the game, but it's
+// turn and hash are not, so it should only be used for checking threats.
+func (b *Board) withMark(p Player, cell Move) Board {
+	after := *b
+	if p == PlayerX {
+		after.x.Set(cell)
+	} else {
+		after.o.Set(cell)
+	}
+
+	after.moveNum++
+	after.updateState()
+	return after
+}
+
+// bitboards returns the Bitboards of the given player and it's opponent.
+func (b *Board) bitboards(p Player) (own, opponent Bitboard) {
+	if p == PlayerX {
+		return b.x, b.o
+	}
+
+	return b.o, b.x
+}
@@ -0,0 +1,130 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package board
+
+import "testing"
+
+func TestBlockingMovesIncludeWins(t *testing.T) {
+	// x can win on 3, while o would fork with 3
+	b := mustNew("xx..o...o")
+	if len(b.ForkingMoves(PlayerO)) == 0 {
+		t.Fatal("o has no forking moves")
+	}
+
+	for _, move := range b.BlockingMoves(PlayerX) {
+		if move == 3 {
+			return
+		}
+	}
+
+	t.Errorf("winning move 3 not in blocking moves %v", b.BlockingMoves(PlayerX))
+}
+
+func TestBlockingMovesDefendThreats(t *testing.T) {
+	for _, b := range reachablePositions() {
+		p := b.Turn()
+		for _, move := range b.BlockingMoves(p) {
+			after := b
+			after.Play(move)
+
+			if after.State() == Unfinished && len(after.Threats(p.Other())) > 0 {
+				t.Errorf("%s: block %d leaves %s an immediate win", b.PositionString(), move, p.Other())
+			}
+		}
+	}
+}
+
+func TestThreats(t *testing.T) {
+	tests := []struct {
+		position string
+		x, o     []Move
+	}{
+		{"x...o....", nil, nil},                // no threats
+		{"xx.oo....", []Move{3}, []Move{6}},    // a threat each
+		{"x.x.o.xo.", []Move{2, 4}, []Move{2}}, // double threat
+		{"xxxoo....", nil, nil},                // finished game
+	}
+
+	for _, test := range tests {
+		b := mustNew(test.position)
+		if got := b.Threats(PlayerX); !equalMoves(got, test.x) {
+			t.Errorf("%s: Threats(x) = %v, want %v", test.position, got, test.x)
+		}
+
+		if got := b.Threats(PlayerO); !equalMoves(got, test.o) {
+			t.Errorf("%s: Threats(o) = %v, want %v", test.position, got, test.o)
+		}
+
+		if got, want := b.HasFork(PlayerX), len(test.x) >= 2; got != want {
+			t.Errorf("%s: HasFork(x) = %t, want %t", test.position, got, want)
+		}
+
+		if got, want := b.HasFork(PlayerO), len(test.o) >= 2; got != want {
+			t.Errorf("%s: HasFork(o) = %t, want %t", test.position, got, want)
+		}
+	}
+}
+
+func TestForkingMoves(t *testing.T) {
+	tests := []struct {
+		position string
+		x, o     []Move
+	}{
+		{".........", nil, nil},                   // no marks to fork with
+		{"x...o...x", []Move{3, 7}, nil},          // opposite corners
+		{"xx.oo....", nil, []Move{3, 7}},          // immediate win on 6 isn't a fork
+		{"x.x.o.xo.", []Move{6, 9}, []Move{4, 6}}, // threats are added to a fork
+		{"xxxoo....", nil, nil},                   // finished game
+	}
+
+	for _, test := range tests {
+		b := mustNew(test.position)
+		if got := b.ForkingMoves(PlayerX); !equalMoves(got, test.x) {
+			t.Errorf("%s: ForkingMoves(x) = %v, want %v", test.position, got, test.x)
+		}
+
+		if got := b.ForkingMoves(PlayerO); !equalMoves(got, test.o) {
+			t.Errorf("%s: ForkingMoves(o) = %v, want %v", test.position, got, test.o)
+		}
+	}
+}
+
+func TestForkingMovesCreateForks(t *testing.T) {
+	for _, b := range reachablePositions() {
+		for _, p := range []Player{PlayerX, PlayerO} {
+			for _, move := range b.ForkingMoves(p) {
+				after := b.withMark(p, move)
+				if after.State() != Unfinished || !after.HasFork(p) {
+					t.Errorf("%s: forking move %d doesn't give %s a fork", b.PositionString(), move, p)
+				}
+			}
+		}
+	}
+}
+
+// equalMoves checks if the given lists contain the same moves in the same
+// order, treating nil and empty lists as equal.
+func equalMoves(a, b []Move) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,252 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compact implements a compact tablebase which only stores the
+// outcome of each position, and optionally the number of steps till the
+// game is decided, indexed by the ternary index of the position. The best
+// moves in a position are reconstructed by probing it's children.
+//
+// A compact table of all the positions is embedded in the package, so it
+// is available without generating the full tablebase.
+package compact
+
+//go:generate go run gen.go
+
+import (
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+// Format represents the encoding of the entries of a Table.
+type Format uint8
+
+// Constants representing the supported formats.
+const (
+	WDL      Format = iota + 1 // 2 bits per position, outcome only
+	Distance                   // 1 byte per position, outcome and steps
+)
+
+// MaxSteps is the maximum number of steps which can be stored in the
+// Distance format, where the steps take up the lower 6 bits of an entry.
+const MaxSteps = 63
+
+// Outcome represents the result of a position with perfect play, relative
+// to the player to move.
+type Outcome uint8
+
+// Constants representing various outcomes.
+const (
+	Unknown Outcome = iota // position is not in the table
+	Win
+	Draw
+	Loss
+)
+
+// String converts an Outcome into it's string representation.
+func (o Outcome) String() string {
+	switch o {
+	case Win:
+		return "win"
+	case Draw:
+		return "draw"
+	case Loss:
+		return "loss"
+	default:
+		return "unknown"
+	}
+}
+
+// Entry represents the data stored about a position in a Table.
+type Entry struct {
+	Outcome Outcome // result with perfect play
+	Steps   int     // steps till the game is decided, if known, else 0
+}
+
+// Table is a compact tablebase, storing a single Entry for each position.
+// The zero value is not usable, and a Table should be created with New or
+// Decode.
+type Table struct {
+	format Format
+	data   []byte
+}
+
+// New creates a new Table with the given format, where the outcomes of all
+// the positions are unknown.
+func New(format Format) *Table {
+	size := board.TernarySize
+	if format == WDL {
+		// 4 entries are packed in each byte
+		size = (size + 3) / 4
+	}
+
+	return &Table{
+		format: format,
+		data:   make([]byte, size),
+	}
+}
+
+// Format returns the format of the Table.
+func (t *Table) Format() Format {
+	return t.format
+}
+
+// Set stores the given evaluation, relative to the player to move, as the
+// Entry of the given position. Evaluations with an unknown outcome are
+// stored as Unknown, so the position is treated as not being in the Table.
+// In the Distance format, it returns an error if the evaluation has more
+// than MaxSteps steps, and the Table is left unchanged.
+func (t *Table) Set(b board.Board, eval evaluation.Rel) error {
+	var outcome Outcome
+	switch eval.Outcome() {
+	case evaluation.Won:
+		outcome = Win
+	case evaluation.Lost:
+		outcome = Loss
+	case evaluation.Drawn:
+		outcome = Draw
+	}
+
+	index := b.Ternary()
+	switch t.format {
+	case WDL:
+		shift := 2 * (index % 4)
+		t.data[index/4] &^= 3 << shift
+		t.data[index/4] |= byte(outcome) << shift
+	case Distance:
+		if eval.Steps() > MaxSteps {
+			return fmt.Errorf("compact: %d steps exceed the maximum of %d", eval.Steps(), MaxSteps)
+		}
+
+		// outcome is stored in the top 2 bits, and the steps in the rest
+		t.data[index] = byte(outcome)<<6 | byte(eval.Steps())
+	}
+
+	return nil
+}
+
+// Probe fetches the Entry of the given position. It returns false as the
+// second argument if the position is not in the Table.
+func (t *Table) Probe(b board.Board) (Entry, bool) {
+	var entry Entry
+
+	index := b.Ternary()
+	switch t.format {
+	case WDL:
+		entry.Outcome = Outcome(t.data[index/4] >> (2 * (index % 4)) & 3)
+	case Distance:
+		entry.Outcome = Outcome(t.data[index] >> 6)
+		entry.Steps = int(t.data[index] & 63)
+	}
+
+	return entry, entry.Outcome != Unknown
+}
+
+// BestMoves returns the best moves in the given position, by probing the
+// positions after each valid move. Wins are preferred over draws, which
+// are preferred over losses. If the table stores the steps, faster wins
+// and slower losses are preferred.
+func (t *Table) BestMoves(b board.Board) []board.Move {
+	var best []board.Move
+	var bestScore int
+
+	for _, move := range b.ValidMoves() {
+		child := b
+		child.Play(move)
+
+		entry, found := t.Probe(child)
+		if !found {
+			continue
+		}
+
+		if score := moveScore(entry); len(best) == 0 || score > bestScore {
+			best = []board.Move{move}
+			bestScore = score
+		} else if score == bestScore {
+			best = append(best, move)
+		}
+	}
+
+	return best
+}
+
+// moveScore converts the Entry of the position after a move, which is
+// relative to the opponent, into a score for the player making the move,
+// where a higher score represents a better move.
+func moveScore(child Entry) int {
+	switch child.Outcome {
+	case Loss:
+		// opponent loses, faster is better
+		return 100 - child.Steps
+	case Win:
+		// opponent wins, slower is better
+		return -100 + child.Steps
+	default:
+		return 0
+	}
+}
+
+// Encode encodes the Table into a byte slice, which can be decoded back
+// into a Table using Decode.
+func (t *Table) Encode() []byte {
+	return append([]byte{byte(t.format)}, t.data...)
+}
+
+// Decode decodes a Table from the given byte slice, which should have been
+// produced by Encode.
+func Decode(data []byte) (*Table, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("compact: empty table")
+	}
+
+	t := New(Format(data[0]))
+	if (t.format != WDL && t.format != Distance) || len(data)-1 != len(t.data) {
+		return nil, fmt.Errorf("compact: invalid table")
+	}
+
+	copy(t.data, data[1:])
+	return t, nil
+}
+
+// embedded is the compact table of all the positions, in the Distance
+// format, generated by gen.go.
+//
+//go:embed table.bin
+var embedded []byte
+
+// defaultTable is the decoded embedded table, which is decoded only once.
+var (
+	defaultOnce  sync.Once
+	defaultTable *Table
+)
+
+// Default returns the embedded compact table of all the positions reachable
+// from the default tic tac toe starting position, in the Distance format.
+// The table is decoded on the first call and shared by all the callers, so
+// it must not be modified.
+func Default() *Table {
+	defaultOnce.Do(func() {
+		t, err := Decode(embedded)
+		if err != nil {
+			// unreachable
+			panic("compact: invalid embedded table")
+		}
+
+		defaultTable = t
+	})
+
+	return defaultTable
+}
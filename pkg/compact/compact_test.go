@@ -0,0 +1,58 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compact
+
+import (
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+func TestSetSteps(t *testing.T) {
+	var b board.Board
+	table := New(Distance)
+
+	if err := table.Set(b, evaluation.WinIn(MaxSteps)); err != nil {
+		t.Fatalf("Set(WinIn(%d)): %v", MaxSteps, err)
+	}
+
+	if entry, _ := table.Probe(b); entry.Outcome != Win || entry.Steps != MaxSteps {
+		t.Fatalf("Probe = %+v, want win in %d", entry, MaxSteps)
+	}
+
+	if err := table.Set(b, evaluation.LossIn(MaxSteps+1)); err == nil {
+		t.Fatalf("Set(LossIn(%d)) didn't fail", MaxSteps+1)
+	}
+
+	// the previous entry should be unchanged
+	if entry, _ := table.Probe(b); entry.Outcome != Win || entry.Steps != MaxSteps {
+		t.Errorf("Probe after failed Set = %+v, want win in %d", entry, MaxSteps)
+	}
+
+	// steps are not stored in the WDL format
+	if err := New(WDL).Set(b, evaluation.WinIn(MaxSteps+1)); err != nil {
+		t.Errorf("WDL Set: %v", err)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	if Default() != Default() {
+		t.Error("Default decoded the embedded table again")
+	}
+
+	if entry, found := Default().Probe(board.Board{}); !found || entry.Outcome != Draw {
+		t.Errorf("starting position = %+v, want draw", entry)
+	}
+}
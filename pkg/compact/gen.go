@@ -0,0 +1,41 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build ignore
+
+// gen generates the compact table embedded in the compact package from the
+// full tablebase. It is run using go generate.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"laptudirm.com/x/wreck/pkg/compact"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func main() {
+	table := compact.New(compact.Distance)
+	for _, data := range tablebase.Generate().Positions() {
+		if err := table.Set(data.Position(), data.RelEval()); err != nil {
+			fmt.Fprintln(os.Stderr, "gen:", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := os.WriteFile("table.bin", table.Encode(), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dataset implements the conversion of evaluated positions into
+// training data for machine learning models, and it's export to various
+// file formats.
+//
+// Each position is converted into feature planes, which consist of the
+// cells marked by player x, the cells marked by player o, and whose turn
+// it is, along with it's labels, which consist of the result relative to
+// the player to move (win, draw or loss), the number of steps till the
+// game is decided, and a policy vector marking the best moves.
+package dataset
+
+import (
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+// Record represents a single training example.
+type Record struct {
+	Position board.Board `json:"-"`
+
+	// features
+	X    [9]float32 `json:"x"`    // 1 for cells marked by player x
+	O    [9]float32 `json:"o"`    // 1 for cells marked by player o
+	Turn float32    `json:"turn"` // 1 if it is x's turn, else 0
+
+	// labels
+	WDL    int        `json:"wdl"`    // 1 for win, 0 for draw, -1 for loss
+	Steps  int        `json:"steps"`  // steps till the game is decided
+	Policy [9]float32 `json:"policy"` // 1 for the best moves
+}
+
+// NewRecord creates a new Record for the given position, with the given
+// absolute evaluation and best moves.
+func NewRecord(b board.Board, eval evaluation.Abs, best []board.Move) Record {
+	r := Record{Position: b}
+
+	r.X, r.O, r.Turn = Features(b)
+
+	rel := evaluation.ToRel(eval, b)
+	switch rel.Outcome() {
+	case evaluation.Won:
+		r.WDL = 1
+	case evaluation.Lost:
+		r.WDL = -1
+	}
+
+	r.Steps = rel.Steps()
+	for _, move := range best {
+		r.Policy[move-1] = 1
+	}
+
+	return r
+}
+
+// Features converts the given position into it's feature planes.
+func Features(b board.Board) (x, o [9]float32, turn float32) {
+	pos := b.PositionString()
+	for i := range pos {
+		switch pos[i] {
+		case 'x':
+			x[i] = 1
+		case 'o':
+			o[i] = 1
+		}
+	}
+
+	if b.XsTurn() {
+		turn = 1
+	}
+
+	return x, o, turn
+}
+
+// Columns returns the names of the values returned by Values.
+func Columns() []string {
+	var columns []string
+	for _, plane := range []string{"x", "o"} {
+		for cell := 1; cell <= 9; cell++ {
+			columns = append(columns, fmt.Sprint(plane, cell))
+		}
+	}
+
+	columns = append(columns, "turn", "wdl", "steps")
+	for cell := 1; cell <= 9; cell++ {
+		columns = append(columns, fmt.Sprint("p", cell))
+	}
+
+	return columns
+}
+
+// Values returns the features and labels of the Record as a flat slice,
+// in the order given by Columns.
+func (r Record) Values() []float32 {
+	values := make([]float32, 0, 30)
+	values = append(values, r.X[:]...)
+	values = append(values, r.O[:]...)
+	values = append(values, r.Turn, float32(r.WDL), float32(r.Steps))
+	values = append(values, r.Policy[:]...)
+	return values
+}
+
+// Format represents a file format which a dataset can be written in.
+type Format int
+
+// Constants representing the supported formats.
+const (
+	CSV   Format = iota // comma separated values with a header
+	NPY                 // NumPy array of float32s
+	JSONL               // JSON object on each line
+)
+
+// ParseFormat parses the name of a Format, which is one of csv, npy and
+// jsonl. It returns false as the second argument if the name is invalid.
+func ParseFormat(name string) (Format, bool) {
+	switch name {
+	case "csv":
+		return CSV, true
+	case "npy":
+		return NPY, true
+	case "jsonl":
+		return JSONL, true
+	default:
+		return 0, false
+	}
+}
+
+// Write writes the given records to the writer in the given format. The
+// CSV and JSON lines formats also include the position string of each
+// record, while the NumPy format contains a 2 dimensional array with a row
+// for each record, whose columns are given by Columns.
+func Write(w io.Writer, format Format, records []Record) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, records)
+	case NPY:
+		return writeNPY(w, records)
+	case JSONL:
+		return writeJSONL(w, records)
+	default:
+		return fmt.Errorf("dataset: invalid format %d", format)
+	}
+}
+
+// writeCSV writes the records as comma separated values.
+func writeCSV(w io.Writer, records []Record) error {
+	out := csv.NewWriter(w)
+	out.Write(append([]string{"position"}, Columns()...))
+
+	for _, r := range records {
+		row := []string{r.Position.PositionString()}
+		for _, value := range r.Values() {
+			row = append(row, strconv.FormatFloat(float64(value), 'g', -1, 32))
+		}
+
+		out.Write(row)
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// writeJSONL writes the records as JSON objects, one on each line.
+func writeJSONL(w io.Writer, records []Record) error {
+	encoder := json.NewEncoder(w)
+	for _, r := range records {
+		err := encoder.Encode(struct {
+			Position string `json:"position"`
+			Record
+		}{r.Position.PositionString(), r})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeNPY writes the records as a version 1.0 NumPy array file.
+func writeNPY(w io.Writer, records []Record) error {
+	columns := len(Columns())
+	header := fmt.Sprintf("{'descr': '<f4', 'fortran_order': False, 'shape': (%d, %d), }", len(records), columns)
+
+	// the magic string, version, header length, header, and the newline
+	// ending the header should have a length divisible by 64
+	const prefix = 10
+	padding := 64 - (prefix+len(header)+1)%64
+	header += strings.Repeat(" ", padding%64) + "\n"
+
+	if _, err := io.WriteString(w, "\x93NUMPY\x01\x00"); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint16(len(header))); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+
+	data := make([]byte, len(records)*columns*4)
+	for i, r := range records {
+		for j, value := range r.Values() {
+			binary.LittleEndian.PutUint32(data[(i*columns+j)*4:], math.Float32bits(value))
+		}
+	}
+
+	_, err := w.Write(data)
+	return err
+}
@@ -0,0 +1,210 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+// testRecords returns a few records for testing the formats.
+func testRecords(t *testing.T) []Record {
+	t.Helper()
+
+	var records []Record
+	for _, test := range []struct {
+		position string
+		eval     evaluation.Abs
+		best     []board.Move
+	}{
+		{".........", evaluation.Abs(evaluation.Draw), []board.Move{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"xo.x.....", evaluation.Abs(evaluation.WinIn(3)), []board.Move{7}},
+		{"xx.oo....", evaluation.Abs(evaluation.WinIn(2)), []board.Move{3}},
+	} {
+		b, err := board.New(test.position)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		records = append(records, NewRecord(b, test.eval, test.best))
+	}
+
+	return records
+}
+
+func TestNewRecord(t *testing.T) {
+	r := testRecords(t)[1] // xo.x....., o to move and lose in 3
+
+	want := Record{
+		Position: r.Position,
+		X:        [9]float32{1, 0, 0, 1, 0, 0, 0, 0, 0},
+		O:        [9]float32{0, 1, 0, 0, 0, 0, 0, 0, 0},
+		Turn:     0,
+		WDL:      -1,
+		Steps:    3,
+		Policy:   [9]float32{0, 0, 0, 0, 0, 0, 1, 0, 0},
+	}
+
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("NewRecord() = %+v, want %+v", r, want)
+	}
+
+	if n := len(r.Values()); n != len(Columns()) {
+		t.Errorf("record has %d values, but there are %d columns", n, len(Columns()))
+	}
+}
+
+func TestNPY(t *testing.T) {
+	records := testRecords(t)
+
+	var buffer bytes.Buffer
+	if err := Write(&buffer, NPY, records); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buffer.Bytes()
+	if !bytes.HasPrefix(data, []byte("\x93NUMPY\x01\x00")) {
+		t.Fatalf("invalid magic string %q", data[:8])
+	}
+
+	length := int(binary.LittleEndian.Uint16(data[8:10]))
+	if (10+length)%64 != 0 {
+		t.Errorf("header ends at %d, which is not 64 byte aligned", 10+length)
+	}
+
+	header := string(data[10 : 10+length])
+	if !strings.HasSuffix(header, "\n") || !strings.Contains(header, "'shape': (3, 30)") ||
+		!strings.Contains(header, "'descr': '<f4'") {
+		t.Errorf("invalid header %#v", header)
+	}
+
+	body := data[10+length:]
+	if len(body) != 3*30*4 {
+		t.Fatalf("array has %d bytes, want %d", len(body), 3*30*4)
+	}
+
+	for i, r := range records {
+		for j, want := range r.Values() {
+			got := math.Float32frombits(binary.LittleEndian.Uint32(body[(i*30+j)*4:]))
+			if got != want {
+				t.Errorf("row %d, column %d: got %v, want %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestNPYAlignment(t *testing.T) {
+	// the header's length depends on the number of records
+	for _, n := range []int{0, 1, 9, 10, 100, 5478} {
+		var buffer bytes.Buffer
+		if err := Write(&buffer, NPY, make([]Record, n)); err != nil {
+			t.Fatal(err)
+		}
+
+		length := int(binary.LittleEndian.Uint16(buffer.Bytes()[8:10]))
+		if (10+length)%64 != 0 || buffer.Len() != 10+length+n*30*4 {
+			t.Errorf("%d records: header length %d, file length %d", n, length, buffer.Len())
+		}
+	}
+}
+
+func TestCSV(t *testing.T) {
+	records := testRecords(t)
+
+	var buffer bytes.Buffer
+	if err := Write(&buffer, CSV, records); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != len(records)+1 {
+		t.Fatalf("got %d rows, want %d", len(rows), len(records)+1)
+	}
+
+	header := append([]string{"position"}, Columns()...)
+	if !reflect.DeepEqual(rows[0], header) {
+		t.Errorf("header = %v, want %v", rows[0], header)
+	}
+
+	want := []string{
+		"xo.x.....",
+		"1", "0", "0", "1", "0", "0", "0", "0", "0", // x
+		"0", "1", "0", "0", "0", "0", "0", "0", "0", // o
+		"0", "-1", "3", // turn, wdl, steps
+		"0", "0", "0", "0", "0", "0", "1", "0", "0", // policy
+	}
+
+	if !reflect.DeepEqual(rows[2], want) {
+		t.Errorf("row = %v, want %v", rows[2], want)
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	records := testRecords(t)
+
+	var buffer bytes.Buffer
+	if err := Write(&buffer, JSONL, records); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != len(records) {
+		t.Fatalf("got %d lines, want %d", len(lines), len(records))
+	}
+
+	var object struct {
+		Position string     `json:"position"`
+		X        []float32  `json:"x"`
+		O        []float32  `json:"o"`
+		Turn     float32    `json:"turn"`
+		WDL      int        `json:"wdl"`
+		Steps    int        `json:"steps"`
+		Policy   [9]float32 `json:"policy"`
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(lines[2]))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&object); err != nil {
+		t.Fatal(err)
+	}
+
+	// xx.oo...., x to move and win in 2
+	if object.Position != "xx.oo...." || len(object.X) != 9 || object.X[1] != 1 || object.O[3] != 1 ||
+		object.Turn != 1 || object.WDL != 1 || object.Steps != 2 || object.Policy[2] != 1 {
+		t.Errorf("unexpected object %+v", object)
+	}
+}
+
+func TestInvalidFormat(t *testing.T) {
+	if _, found := ParseFormat("bogus"); found {
+		t.Error("ParseFormat(\"bogus\") succeeded")
+	}
+
+	if err := Write(&bytes.Buffer{}, Format(-1), nil); err == nil {
+		t.Error("Write() with an invalid format didn't fail")
+	}
+}
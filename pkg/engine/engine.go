@@ -0,0 +1,170 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package engine implements players which choose moves in a tic tac toe
+// position, and matches between them, so that different ways of playing
+// can be compared against each other.
+package engine
+
+import (
+	"math/rand"
+
+	"laptudirm.com/x/wreck/pkg/board"
+)
+
+// Player represents anything which can choose a move in a position.
+type Player interface {
+	// Name returns the name of the player, used in match reports.
+	Name() string
+
+	// Move returns the move chosen by the player in the given position,
+	// which should be unfinished.
+	Move(board.Board) board.Move
+}
+
+// Oracle provides the best moves in a position, like a tablebase.
+type Oracle interface {
+	BestMoves(board.Board) []board.Move
+}
+
+// perfect is a Player which plays a random best move given by an Oracle.
+type perfect struct {
+	oracle Oracle
+	rand   *rand.Rand
+}
+
+// NewPerfect creates a new Player which plays perfectly using the given
+// Oracle. Ties between the best moves are broken randomly using the given
+// seed.
+func NewPerfect(oracle Oracle, seed int64) Player {
+	return &perfect{
+		oracle: oracle,
+		rand:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Name returns the name of the Player.
+func (p *perfect) Name() string {
+	return "perfect"
+}
+
+// Move returns a random best move in the given position.
+func (p *perfect) Move(b board.Board) board.Move {
+	moves := p.oracle.BestMoves(b)
+	if len(moves) == 0 {
+		moves = b.ValidMoves()
+	}
+
+	return moves[p.rand.Intn(len(moves))]
+}
+
+// random is a Player which plays uniformly random moves.
+type random struct {
+	rand *rand.Rand
+}
+
+// NewRandom creates a new Player which plays uniformly random valid moves
+// chosen using the given seed.
+func NewRandom(seed int64) Player {
+	return &random{
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Name returns the name of the Player.
+func (p *random) Name() string {
+	return "random"
+}
+
+// Move returns a random valid move in the given position.
+func (p *random) Move(b board.Board) board.Move {
+	moves := b.ValidMoves()
+	return moves[p.rand.Intn(len(moves))]
+}
+
+// Game plays a game from the given position between the given players,
+// where x plays the moves of player x and o plays the moves of player o.
+// It returns the moves which were played and the final state of the game.
+func Game(start board.Board, x, o Player) ([]board.Move, board.State) {
+	var moves []board.Move
+
+	b := start
+	for b.State() == board.Unfinished {
+		player := x
+		if !b.XsTurn() {
+			player = o
+		}
+
+		move := player.Move(b)
+		if err := b.Play(move); err != nil {
+			// invalid moves forfeit the game
+			if b.XsTurn() {
+				return moves, board.PlayerOWon
+			}
+
+			return moves, board.PlayerXWon
+		}
+
+		moves = append(moves, move)
+	}
+
+	return moves, b.State()
+}
+
+// Result represents the result of a match from the perspective of it's
+// first player.
+type Result struct {
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+// Games returns the number of games played in the match.
+func (r Result) Games() int {
+	return r.Wins + r.Draws + r.Losses
+}
+
+// Score returns the fraction of the available points which were scored,
+// where a win is worth 1 point and a draw is worth half a point.
+func (r Result) Score() float64 {
+	if r.Games() == 0 {
+		return 0
+	}
+
+	return (float64(r.Wins) + float64(r.Draws)/2) / float64(r.Games())
+}
+
+// Match plays the given number of games from the given position between
+// the given players, who alternate playing as player x. It returns the
+// result of the match from the perspective of player a.
+func Match(start board.Board, a, b Player, games int) Result {
+	var result Result
+	for i := 0; i < games; i++ {
+		x, o := a, b
+		if i%2 == 1 {
+			x, o = b, a
+		}
+
+		_, state := Game(start, x, o)
+		switch {
+		case state == board.GameDrawn:
+			result.Draws++
+		case (state == board.PlayerXWon) == (x == a):
+			result.Wins++
+		default:
+			result.Losses++
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,156 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"math/rand"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+// Handicap represents the controlled errors made by a handicapped Player.
+type Handicap struct {
+	// ErrorRate is the probability of deliberately playing a move which
+	// isn't the best one that the player can see.
+	ErrorRate float64
+
+	// MaxPenalty is the largest penalty, see evaluation.Penalty, of a
+	// move which is played deliberately. It limits how much the outcome
+	// of the position can drop due to a single error.
+	MaxPenalty float64
+
+	// Depth is the number of moves the player can see ahead. Wins and
+	// losses which are decided after more moves look like draws to the
+	// player. A Depth of 0 means the player can see till the end.
+	Depth int
+}
+
+// MinLevel and MaxLevel are the weakest and strongest levels.
+const (
+	MinLevel = 1
+	MaxLevel = 10
+)
+
+// levels contains the Handicap of each level.
+var levels = [MaxLevel + 1]Handicap{
+	1:  {ErrorRate: 0.9, MaxPenalty: 1, Depth: 1},
+	2:  {ErrorRate: 0.8, MaxPenalty: 1, Depth: 2},
+	3:  {ErrorRate: 0.7, MaxPenalty: 1, Depth: 2},
+	4:  {ErrorRate: 0.6, MaxPenalty: 0.5, Depth: 3},
+	5:  {ErrorRate: 0.5, MaxPenalty: 0.5, Depth: 3},
+	6:  {ErrorRate: 0.4, MaxPenalty: 0.5, Depth: 4},
+	7:  {ErrorRate: 0.3, MaxPenalty: evaluation.DelayPenalty, Depth: 5},
+	8:  {ErrorRate: 0.2, MaxPenalty: evaluation.DelayPenalty, Depth: 6},
+	9:  {ErrorRate: 0.1, MaxPenalty: evaluation.DelayPenalty},
+	10: {},
+}
+
+// Level returns the Handicap of the given strength level, from MinLevel to
+// MaxLevel. The strongest level has no handicap and plays perfectly.
+func Level(level int) (Handicap, error) {
+	if level < MinLevel || level > MaxLevel {
+		return Handicap{}, fmt.Errorf("engine: invalid level %d", level)
+	}
+
+	return levels[level], nil
+}
+
+// handicapped is a Player which chooses it's moves from a tablebase, but
+// makes errors according to a Handicap.
+type handicapped struct {
+	name     string
+	table    *tablebase.Table
+	handicap Handicap
+	rand     *rand.Rand
+}
+
+// NewLevel creates a new Player which plays at the given strength level,
+// from MinLevel to MaxLevel, using the given tablebase. The errors are
+// chosen randomly using the given seed, so games are reproducible.
+func NewLevel(table *tablebase.Table, level int, seed int64) (Player, error) {
+	handicap, err := Level(level)
+	if err != nil {
+		return nil, err
+	}
+
+	p := NewHandicapped(table, handicap, seed).(*handicapped)
+	p.name = fmt.Sprintf("level %d", level)
+	return p, nil
+}
+
+// NewHandicapped creates a new Player which makes errors according to the
+// given Handicap, using the given tablebase and seed.
+func NewHandicapped(table *tablebase.Table, handicap Handicap, seed int64) Player {
+	return &handicapped{
+		name:     "handicapped",
+		table:    table,
+		handicap: handicap,
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Name returns the name of the Player.
+func (p *handicapped) Name() string {
+	return p.name
+}
+
+// Move returns the move chosen by the Player in the given position. The
+// Player plays a random move out of the ones which look best to it, or
+// with a probability of the ErrorRate, a random move which looks worse but
+// is within the MaxPenalty.
+func (p *handicapped) Move(b board.Board) board.Move {
+	qualities := p.table.MoveQualities(b)
+	if len(qualities) == 0 {
+		moves := b.ValidMoves()
+		return moves[p.rand.Intn(len(moves))]
+	}
+
+	var seen evaluation.Rel
+	for i, quality := range qualities {
+		if eval := p.horizon(quality.Eval); i == 0 || eval.Compare(seen) > 0 {
+			seen = eval
+		}
+	}
+
+	var best, errors []board.Move
+	for _, quality := range qualities {
+		switch {
+		case p.horizon(quality.Eval).Compare(seen) == 0:
+			best = append(best, quality.Move)
+		case quality.Penalty <= p.handicap.MaxPenalty:
+			errors = append(errors, quality.Move)
+		}
+	}
+
+	candidates := best
+	if len(errors) > 0 && p.rand.Float64() < p.handicap.ErrorRate {
+		candidates = errors
+	}
+
+	return candidates[p.rand.Intn(len(candidates))]
+}
+
+// horizon converts the evaluation of a move into the evaluation seen by
+// the Player, where wins and losses beyond it's Depth look like draws.
+func (p *handicapped) horizon(eval evaluation.Rel) evaluation.Rel {
+	// a move which wins immediately has 2 steps, and is 1 move deep
+	if p.handicap.Depth > 0 && eval.Outcome() != evaluation.Unknown && eval.Steps()-1 > p.handicap.Depth {
+		return evaluation.Draw
+	}
+
+	return eval
+}
@@ -0,0 +1,74 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func TestMaxLevelIsPerfect(t *testing.T) {
+	table := tablebase.Generate()
+	for _, seed := range []int64{1, 2, 3} {
+		player, err := NewLevel(table, MaxLevel, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, data := range table.Positions() {
+			position := data.Position()
+			if position.State() != board.Unfinished {
+				continue
+			}
+
+			move := player.Move(position)
+			if penalty, valid := data.Penalty(move); !valid || penalty > 0 {
+				t.Fatalf("seed %d: %s: level %d played %d with penalty %v",
+					seed, position.PositionString(), MaxLevel, move, penalty)
+			}
+		}
+	}
+}
+
+func TestMinLevelLoses(t *testing.T) {
+	const games = 100
+
+	table := tablebase.Generate()
+	player, err := NewLevel(table, MinLevel, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var start board.Board // zero value is starting board
+	result := Match(start, player, NewPerfect(table, 1), games)
+
+	if result.Wins > 0 {
+		t.Errorf("level %d won %d games against perfect play", MinLevel, result.Wins)
+	}
+
+	if result.Losses < games/2 {
+		t.Errorf("level %d lost only %d of %d games against perfect play", MinLevel, result.Losses, games)
+	}
+}
+
+func TestLevelRange(t *testing.T) {
+	table := tablebase.Generate()
+	for _, level := range []int{MinLevel - 1, MaxLevel + 1} {
+		if _, err := NewLevel(table, level, 1); err == nil {
+			t.Errorf("NewLevel(%d) didn't fail", level)
+		}
+	}
+}
@@ -14,80 +14,332 @@
 // Package evaluation implements various functions and types relating to
 // evaluating a tic tac toe position, and manipulating that evaluation. It
 // also contains constants representing various evaluations.
+//
+// An evaluation explicitly stores the outcome of a position with perfect
+// play, the number of steps after which a won or lost game is decided, and
+// a heuristic score for positions whose outcome is unknown. This allows
+// evaluations to represent games of any length, on any size of board.
 package evaluation
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 
 	"laptudirm.com/x/wreck/pkg/board"
 )
 
+// Outcome represents the result of a position with perfect play, from the
+// perspective of a player.
+type Outcome int8
+
+// Constants representing various outcomes. The zero value is a draw, so
+// the zero value of an evaluation is a drawn evaluation.
+const (
+	Drawn   Outcome = iota // game is drawn
+	Won                    // game is won by the player
+	Lost                   // game is lost by the player
+	Unknown                // result is unknown, only estimated by a score
+)
+
+// String converts an Outcome into it's string representation.
+func (o Outcome) String() string {
+	switch o {
+	case Drawn:
+		return "draw"
+	case Won:
+		return "win"
+	case Lost:
+		return "loss"
+	case Unknown:
+		return "unknown"
+	default:
+		return "invalid outcome"
+	}
+}
+
 // eval represents the underlying type of all evaluation types.
-type eval int8
+type eval struct {
+	outcome  Outcome
+	distance int   // steps till the game is decided, for wins and losses
+	score    Score // heuristic score, for unknown outcomes
+}
 
-// Rel represents a relative position evaluation.
+// Rel represents a relative position evaluation, from the perspective of
+// the player to move.
 type Rel eval
 
 // relative evaluations representing various states
-const (
-	Draw    Rel = 0
-	WinIn1  Rel = 10
-	LossIn1 Rel = -10
+var (
+	Draw    = Rel{outcome: Drawn}
+	WinIn1  = WinIn(1)
+	LossIn1 = LossIn(1)
 )
 
-// Abs represents an absolute position evaluation.
+// WinIn returns the relative evaluation of a position which is won by the
+// player to move after the given number of steps.
+func WinIn(steps int) Rel {
+	return Rel{outcome: Won, distance: steps}
+}
+
+// LossIn returns the relative evaluation of a position which is lost by the
+// player to move after the given number of steps.
+func LossIn(steps int) Rel {
+	return Rel{outcome: Lost, distance: steps}
+}
+
+// Estimate returns the relative evaluation of a position whose outcome is
+// unknown, and has the given heuristic score. Exact scores are converted
+// into the wins or losses which they represent.
+func Estimate(s Score) Rel {
+	switch {
+	case s > MaxHeuristic:
+		return WinIn(int(ScoreWin - s))
+	case s < -MaxHeuristic:
+		return LossIn(int(ScoreWin + s))
+	default:
+		return Rel{outcome: Unknown, score: s}
+	}
+}
+
+// Outcome returns the outcome of the position for the player to move.
+func (r Rel) Outcome() Outcome {
+	return r.outcome
+}
+
+// Steps returns the number of steps after which the game is decided in
+// favour of one of the players. A position where the game has been won has
+// a distance of 1 step, and it increases by 1 for each move before it. It
+// returns 0 for drawn positions and positions with an unknown outcome.
+func (r Rel) Steps() int {
+	return eval(r).steps()
+}
+
+// Score returns the heuristic score of a position with an unknown outcome.
+// It returns 0 for positions whose outcome is known.
+func (r Rel) Score() Score {
+	return r.score
+}
+
+// Compare compares the relative evaluation with the given one. It returns
+// 1 if the evaluation is better for the player to move than the given one,
+// -1 if it is worse, and 0 if they are equally good.
+func (r Rel) Compare(o Rel) int {
+	return eval(r).compare(eval(o))
+}
+
+// String returns the string representation of the given relative
+// evaluation, which uses the same notation as an absolute evaluation.
+func (r Rel) String() string {
+	return eval(r).String()
+}
+
+// Abs represents an absolute position evaluation, where the outcome is
+// from the perspective of player x. A position won by player o has a Lost
+// outcome.
 type Abs eval
 
+// Outcome returns the outcome of the position for player x.
+func (a Abs) Outcome() Outcome {
+	return a.outcome
+}
+
+// Steps returns the number of steps after which the game is decided in
+// favour of one of the players. See Rel.Steps.
+func (a Abs) Steps() int {
+	return eval(a).steps()
+}
+
+// Score returns the heuristic score of a position with an unknown outcome,
+// from the perspective of player x. It returns 0 for positions whose
+// outcome is known.
+func (a Abs) Score() Score {
+	return a.score
+}
+
+// Compare compares the absolute evaluation with the given one. It returns
+// 1 if the evaluation is better for player x than the given one, -1 if it
+// is worse, and 0 if they are equally good.
+func (a Abs) Compare(o Abs) int {
+	return eval(a).compare(eval(o))
+}
+
 // String returns the string representation of the given absolute
 // evaluation.
 func (a Abs) String() string {
-	switch {
-	case a == 0:
+	return eval(a).String()
+}
+
+// EvalError is the error reported when an invalid evaluation string is
+// provided to ParseAbs.
+type EvalError struct {
+	eval string
+}
+
+func (e EvalError) Error() string {
+	return fmt.Sprintf("evaluation: invalid evaluation string %#v", e.eval)
+}
+
+// ParseAbs parses an absolute evaluation from it's string representation,
+// which is the format produced by Abs.String. A drawn evaluation may also
+// be written in the ASCII forms =00, +00, and -00. It returns an EvalError
+// if the given string is invalid.
+func ParseAbs(s string) (Abs, error) {
+	switch s {
+	case "±00", "=00", "+00", "-00":
+		return Abs(Draw), nil
+	}
+
+	if len(s) < 2 || (s[0] != '+' && s[0] != '-') {
+		return Abs{}, EvalError{s}
+	}
+
+	if s[1] == 'W' {
+		steps, err := strconv.Atoi(s[2:])
+		if err != nil || steps < 1 || strings.HasPrefix(s[2:], "+") {
+			return Abs{}, EvalError{s}
+		}
+
+		if s[0] == '+' {
+			return Abs(WinIn(steps)), nil
+		}
+
+		return Abs(LossIn(steps)), nil
+	}
+
+	// heuristic score, in units of 100
+	score, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(score) || s[1] == '+' || s[1] == '-' {
+		return Abs{}, EvalError{s}
+	}
+
+	if score = math.Round(score * 100); score > float64(MaxHeuristic) || score < -float64(MaxHeuristic) {
+		return Abs{}, EvalError{s}
+	}
+
+	return Abs{outcome: Unknown, score: Score(score)}, nil
+}
+
+// steps returns the number of steps after which the game is decided.
+func (e eval) steps() int {
+	switch e.outcome {
+	case Won, Lost:
+		return e.distance
+	default:
+		return 0
+	}
+}
+
+// rank returns the rank of the evaluation's outcome, where a loss ranks
+// below a draw and an unknown outcome, which rank below a win.
+func (e eval) rank() int {
+	switch e.outcome {
+	case Won:
+		return 1
+	case Lost:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// compare compares the evaluation with the given one from the perspective
+// of the player they are relative to. See Rel.Compare.
+func (e eval) compare(o eval) int {
+	if e.rank() != o.rank() {
+		return sign(e.rank() - o.rank())
+	}
+
+	switch e.outcome {
+	case Won:
+		// faster wins are better
+		return sign(o.distance - e.distance)
+	case Lost:
+		// slower losses are better
+		return sign(e.distance - o.distance)
+	default:
+		// draws have a score of 0
+		return sign(int(e.score) - int(o.score))
+	}
+}
+
+// negate returns the evaluation from the perspective of the opponent,
+// without changing the position it refers to.
+func (e eval) negate() eval {
+	switch e.outcome {
+	case Won:
+		e.outcome = Lost
+	case Lost:
+		e.outcome = Won
+	}
+
+	e.score = -e.score
+	return e
+}
+
+// String returns the string representation of the evaluation.
+func (e eval) String() string {
+	switch e.outcome {
+	case Drawn:
 		return "±00"
-	case a > 0:
-		steps := 11 - a
-		return fmt.Sprintf("+W%d", steps)
-	case a < 0:
-		steps := 11 + a
-		return fmt.Sprintf("-W%d", steps)
+	case Won:
+		return fmt.Sprintf("+W%d", e.distance)
+	case Lost:
+		return fmt.Sprintf("-W%d", e.distance)
+	case Unknown:
+		return e.score.String()
 	default:
 		return "invalid"
 	}
 }
 
-// ToRel converts a absolute position evaluation, where positive numbers
-// represent a win for x and negative numbers represent a win for o to a
-// turn relative evaluation where a positive number represents a win and a
-// negative number represents a loss for the current player.
+// sign returns the sign of the given number.
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// ToRel converts a absolute position evaluation, where a win represents a
+// win for x and a loss represents a win for o, to a turn relative
+// evaluation where a win represents a win and a loss represents a loss for
+// the current player.
 func ToRel(a Abs, b board.Board) Rel {
-	r := Rel(a)
 	if b.XsTurn() {
-		return r
+		return Rel(a)
 	}
 
-	return -r
+	return Rel(eval(a).negate())
 }
 
-// ToAbs converts a turn relative evaluation where a positive number
-// represents a win and a negative number represents a loss for the current
-// player to an absolute position evaluation, where positive numbers
-// represent a win for x and negative numbers represent a win for o.
+// ToAbs converts a turn relative evaluation where a win represents a win
+// and a loss represents a loss for the current player to an absolute
+// position evaluation, where a win represents a win for x and a loss
+// represents a win for o.
 func ToAbs(r Rel, b board.Board) Abs {
-	a := Abs(r)
 	if b.XsTurn() {
-		return a
+		return Abs(r)
 	}
 
-	return -a
+	return Abs(eval(r).negate())
 }
 
 // Flip flips a relative evaluation to be from the perspective of the
 // opponent, where a win for the current player turns into a loss for the
-// opponent and vice-versa.
-func Flip(e Rel) Rel {
-	if e = -e; e > Draw {
-		e--
+// opponent and vice-versa. It is used to convert the evaluation of the
+// position after a move into the evaluation of the move, so a loss for the
+// opponent is a win which takes one more step.
+func Flip(r Rel) Rel {
+	e := eval(r).negate()
+	if e.outcome == Won {
+		e.distance++
 	}
 
-	return e
+	return Rel(e)
 }
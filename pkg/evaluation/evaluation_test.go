@@ -0,0 +1,192 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b Rel
+		want int
+	}{
+		// faster wins are better, however long the game is
+		{WinIn(3), WinIn(100), 1},
+		{WinIn(100), WinIn(3), -1},
+		{WinIn(1000000), WinIn(1000001), 1},
+		{WinIn(5), WinIn(5), 0},
+
+		// slower losses are better
+		{LossIn(100), LossIn(3), 1},
+		{LossIn(3), LossIn(100), -1},
+		{LossIn(5), LossIn(5), 0},
+
+		// any win is better than a draw, which is better than any loss
+		{WinIn(1000), Draw, 1},
+		{Draw, LossIn(1000), 1},
+		{WinIn(1000), LossIn(1), 1},
+		{Draw, Draw, 0},
+
+		// heuristic scores rank with draws, between wins and losses
+		{Estimate(35), Estimate(-35), 1},
+		{Estimate(35), Draw, 1},
+		{Draw, Estimate(-35), 1},
+		{WinIn(1000), Estimate(MaxHeuristic), 1},
+		{Estimate(-MaxHeuristic), LossIn(1000), 1},
+	}
+
+	for _, test := range tests {
+		if got := test.a.Compare(test.b); got != test.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", test.a, test.b, got, test.want)
+		}
+
+		if got := test.b.Compare(test.a); got != -test.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", test.b, test.a, got, -test.want)
+		}
+
+		if got := Abs(test.a).Compare(Abs(test.b)); got != test.want {
+			t.Errorf("Abs %s.Compare(%s) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestLargeDistances(t *testing.T) {
+	for _, d := range []int{1, 2, 9, 10, 100, 1 << 20} {
+		if LossIn(d).Compare(Draw) >= 0 || Draw.Compare(WinIn(d)) >= 0 {
+			t.Errorf("LossIn(%d) < Draw < WinIn(%d) doesn't hold", d, d)
+		}
+
+		if got := WinIn(d).Steps(); got != d {
+			t.Errorf("WinIn(%d).Steps() = %d", d, got)
+		}
+
+		if got := LossIn(d).Steps(); got != d {
+			t.Errorf("LossIn(%d).Steps() = %d", d, got)
+		}
+	}
+}
+
+func TestFlip(t *testing.T) {
+	tests := []struct {
+		r, want Rel
+	}{
+		{LossIn(1), WinIn(2)},
+		{LossIn(100), WinIn(101)},
+		{WinIn(1), LossIn(1)},
+		{WinIn(100), LossIn(100)},
+		{Draw, Draw},
+		{Estimate(35), Estimate(-35)},
+	}
+
+	for _, test := range tests {
+		if got := Flip(test.r); got != test.want {
+			t.Errorf("Flip(%s) = %s, want %s", test.r, got, test.want)
+		}
+	}
+}
+
+func TestRelAbs(t *testing.T) {
+	xToMove := board.Board{}
+	oToMove := board.Board{}
+	oToMove.Play(5)
+
+	evals := []Rel{Draw, WinIn(1), WinIn(100), LossIn(3), Estimate(-250)}
+	for _, r := range evals {
+		if got := ToAbs(r, xToMove); got != Abs(r) {
+			t.Errorf("ToAbs(%s) with x to move = %s", r, got)
+		}
+
+		for _, b := range []board.Board{xToMove, oToMove} {
+			if got := ToRel(ToAbs(r, b), b); got != r {
+				t.Errorf("ToRel(ToAbs(%s)) = %s", r, got)
+			}
+
+			if got := ToAbs(ToRel(Abs(r), b), b); got != Abs(r) {
+				t.Errorf("ToAbs(ToRel(%s)) = %s", r, got)
+			}
+		}
+	}
+
+	// evaluations relative to o are the opposite for x
+	tests := []struct {
+		r    Rel
+		want Abs
+	}{
+		{WinIn(2), Abs(LossIn(2))},
+		{LossIn(7), Abs(WinIn(7))},
+		{Draw, Abs(Draw)},
+		{Estimate(35), Abs(Estimate(-35))},
+	}
+
+	for _, test := range tests {
+		if got := ToAbs(test.r, oToMove); got != test.want {
+			t.Errorf("ToAbs(%s) with o to move = %s, want %s", test.r, got, test.want)
+		}
+	}
+}
+
+func TestParseAbs(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Abs
+	}{
+		{"±00", Abs(Draw)},
+		{"=00", Abs(Draw)},
+		{"+00", Abs(Draw)},
+		{"-00", Abs(Draw)},
+		{"+W1", Abs(WinIn(1))},
+		{"+W3", Abs(WinIn(3))},
+		{"-W2", Abs(LossIn(2))},
+		{"+0.35", Abs(Estimate(35))},
+		{"-1.00", Abs(Estimate(-100))},
+		{"+0.00", Abs(Estimate(0))},
+	}
+
+	for _, test := range tests {
+		got, err := ParseAbs(test.s)
+		if err != nil {
+			t.Errorf("ParseAbs(%#v): %v", test.s, err)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("ParseAbs(%#v) = %s, want %s", test.s, got, test.want)
+		}
+	}
+}
+
+func TestParseAbsRoundTrip(t *testing.T) {
+	for _, eval := range []Abs{Abs(Draw), Abs(WinIn(7)), Abs(LossIn(4)), Abs(Estimate(-250))} {
+		if got, err := ParseAbs(eval.String()); err != nil || got != eval {
+			t.Errorf("ParseAbs(%#v) = %s, %v", eval.String(), got, err)
+		}
+	}
+}
+
+func TestParseAbsInvalid(t *testing.T) {
+	for _, s := range []string{
+		"", "+", "-", "W3", "00", "±0", "=0",
+		"+W", "+W0", "-W-1", "+W+3", "+Wx", "*W3",
+		"0.35", "+abc", "+NaN", "++1", "+-1", "+100.01",
+	} {
+		if eval, err := ParseAbs(s); err == nil {
+			t.Errorf("ParseAbs(%#v) = %s, want error", s, eval)
+		} else if _, ok := err.(EvalError); !ok {
+			t.Errorf("ParseAbs(%#v) returned %T, want EvalError", s, err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"fmt"
+
+	"laptudirm.com/x/wreck/pkg/board"
+)
+
+// Score represents a heuristic evaluation of a position, relative to the
+// player to move, in centipawn-like units where 100 is roughly a certain
+// win. Heuristic scores lie within ±MaxHeuristic, while exact results are
+// encoded outside of that range, so the two can be compared and mixed in a
+// depth limited search.
+type Score int32
+
+// constants representing the ranges of scores
+const (
+	// MaxHeuristic is the largest magnitude of a heuristic score.
+	MaxHeuristic Score = 10000
+
+	// ScoreWin is the score of a position which was won 0 steps ago. A win
+	// in n steps has a score of ScoreWin - n, and a loss in n steps has a
+	// score of -(ScoreWin - n), so faster wins score higher.
+	ScoreWin Score = 1000000
+)
+
+// FromRel converts a relative evaluation into a Score. Evaluations with an
+// unknown outcome are converted into their heuristic score.
+func FromRel(r Rel) Score {
+	switch r.Outcome() {
+	case Won:
+		return ScoreWin - Score(r.Steps())
+	case Lost:
+		return -ScoreWin + Score(r.Steps())
+	case Unknown:
+		return r.Score()
+	default:
+		return 0
+	}
+}
+
+// IsExact checks if the Score represents an exact win or loss, instead of
+// a heuristic estimate.
+func (s Score) IsExact() bool {
+	return s > MaxHeuristic || s < -MaxHeuristic
+}
+
+// String converts a Score into it's string representation. Exact results
+// are represented like relative evaluations, as +Wn or -Wn, while
+// heuristic scores are represented in units of 100, like +0.35.
+func (s Score) String() string {
+	switch {
+	case s > MaxHeuristic:
+		return fmt.Sprintf("+W%d", ScoreWin-s)
+	case s < -MaxHeuristic:
+		return fmt.Sprintf("-W%d", ScoreWin+s)
+	default:
+		return fmt.Sprintf("%+.2f", float64(s)/100)
+	}
+}
+
+// Feature represents a property of a position used by the heuristic
+// evaluation. Each feature is measured relative to the player to move, as
+// the difference between it's value for the player and their opponent.
+type Feature int
+
+// Constants representing the various features.
+const (
+	OpenOnes    Feature = iota // lines with one mark and no opponent marks
+	OpenTwos                   // lines with two marks and no opponent marks
+	Center                     // whether the center is marked
+	Corners                    // number of marked corners
+	Threats                    // cells where a player would complete a line
+	Forks                      // moves which create a fork for a player
+	Tempo                      // always 1, the advantage of moving next
+	NumFeatures                // number of features
+)
+
+// String converts a Feature into it's string representation.
+func (f Feature) String() string {
+	switch f {
+	case OpenOnes:
+		return "open-ones"
+	case OpenTwos:
+		return "open-twos"
+	case Center:
+		return "center"
+	case Corners:
+		return "corners"
+	case Threats:
+		return "threats"
+	case Forks:
+		return "forks"
+	case Tempo:
+		return "tempo"
+	default:
+		return "invalid feature"
+	}
+}
+
+// Features returns the values of the heuristic features of the given
+// position, relative to the player to move.
+func Features(b board.Board) [NumFeatures]float64 {
+	var features [NumFeatures]float64
+
+	player := b.Turn()
+	for i, p := range []board.Player{player, player.Other()} {
+		// own features are added, and opponent's features are subtracted
+		sign := 1.0
+		if i == 1 {
+			sign = -1
+		}
+
+		marks := b.Marks(p)
+
+		features[OpenOnes] += sign * float64(b.OpenLines(p, 1))
+		features[OpenTwos] += sign * float64(b.OpenLines(p, 2))
+
+		if marks.Has(5) {
+			features[Center] += sign
+		}
+
+		for _, corner := range []board.Move{1, 3, 7, 9} {
+			if marks.Has(corner) {
+				features[Corners] += sign
+			}
+		}
+
+		features[Threats] += sign * float64(len(b.Threats(p)))
+		features[Forks] += sign * float64(len(b.ForkingMoves(p)))
+	}
+
+	features[Tempo] = 1
+	return features
+}
+
+// Weights represents the weight of each Feature in a heuristic evaluation,
+// in centipawn-like units.
+type Weights [NumFeatures]float64
+
+// DefaultWeights are the Weights fitted by Tune, with a lambda of 1, against
+// the results of all the positions in the 3x3 tablebase. They can be
+// refitted with the tune subcommand of wreck.
+var DefaultWeights = Weights{
+	OpenOnes: 10.11,
+	OpenTwos: 2.36,
+	Center:   -14.05,
+	Corners:  -5.99,
+	Threats:  55.05,
+	Forks:    5.96,
+	Tempo:    71.62,
+}
+
+// Evaluate returns the heuristic Score of the given position, relative to
+// the player to move. The Score of a finished game is exact.
+func (w Weights) Evaluate(b board.Board) Score {
+	switch b.State() {
+	case board.Unfinished:
+	case board.GameDrawn:
+		return 0
+	default:
+		// last move won the game
+		return FromRel(LossIn1)
+	}
+
+	var sum float64
+	for i, feature := range Features(b) {
+		sum += w[i] * feature
+	}
+
+	score := Score(sum)
+	switch {
+	case score > MaxHeuristic:
+		return MaxHeuristic
+	case score < -MaxHeuristic:
+		return -MaxHeuristic
+	default:
+		return score
+	}
+}
+
+// Heuristic returns the heuristic Score of the given position, relative to
+// the player to move, using the DefaultWeights.
+func Heuristic(b board.Board) Score {
+	return DefaultWeights.Evaluate(b)
+}
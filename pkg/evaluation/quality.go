@@ -0,0 +1,109 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import "math"
+
+// DelayPenalty is the largest penalty given to a move which keeps the
+// outcome of the position, but delays a win or speeds up a loss. It is
+// less than the penalty of changing the outcome, so such moves are always
+// better than moves which change the outcome.
+const DelayPenalty = 0.25
+
+// Penalty returns the quality of a move with the given evaluation, compared
+// to the evaluation of the best move in the position, both relative to the
+// player making the move. The quality is normalized to the range [0, 1],
+// where 0 is an optimal move and 1 is a move which turns a win into a loss.
+//
+// Changing the outcome of the position, from a win into a draw or a draw
+// into a loss, is penalized by 0.5 per step. Moves which keep the outcome
+// but delay a win or speed up a loss are penalized by less than
+// DelayPenalty, depending on the fraction of the steps which are lost.
+// Positions with an unknown outcome are compared by their heuristic score,
+// where a score of 100 is treated like a win.
+func Penalty(best, move Rel) float64 {
+	if move.Compare(best) >= 0 {
+		return 0
+	}
+
+	if best.Outcome() == move.Outcome() {
+		switch best.Outcome() {
+		case Won:
+			// win is delayed
+			return DelayPenalty * float64(move.Steps()-best.Steps()) / float64(move.Steps())
+		case Lost:
+			// loss is sped up
+			return DelayPenalty * float64(best.Steps()-move.Steps()) / float64(best.Steps())
+		}
+	}
+
+	return math.Min(1, (value(best)-value(move))/2)
+}
+
+// value converts an evaluation into the expected result of the game in the
+// range [-1, 1], where 1 is a win and -1 is a loss.
+func value(r Rel) float64 {
+	switch r.Outcome() {
+	case Won:
+		return 1
+	case Lost:
+		return -1
+	case Unknown:
+		return math.Max(-1, math.Min(1, float64(r.Score())/100))
+	default:
+		return 0
+	}
+}
+
+// Grade represents a classification of moves by their quality, for use in
+// user interfaces.
+type Grade int
+
+// Constants representing various grades.
+const (
+	Best       Grade = iota // optimal move
+	Inaccuracy              // keeps the outcome, but slower than the best
+	Mistake                 // worsens the outcome by a step
+	Blunder                 // turns a win into a loss
+)
+
+// GradePenalty classifies a move by it's Penalty.
+func GradePenalty(penalty float64) Grade {
+	switch {
+	case penalty <= 0:
+		return Best
+	case penalty <= DelayPenalty:
+		return Inaccuracy
+	case penalty < 1:
+		return Mistake
+	default:
+		return Blunder
+	}
+}
+
+// String converts a Grade into it's string representation.
+func (g Grade) String() string {
+	switch g {
+	case Best:
+		return "best"
+	case Inaccuracy:
+		return "inaccuracy"
+	case Mistake:
+		return "mistake"
+	case Blunder:
+		return "blunder"
+	default:
+		return "invalid grade"
+	}
+}
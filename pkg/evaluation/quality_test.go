@@ -0,0 +1,60 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import "testing"
+
+func TestPenalty(t *testing.T) {
+	tests := []struct {
+		name       string
+		best, move Rel
+		penalty    float64
+		grade      Grade
+	}{
+		{"best win", WinIn(3), WinIn(3), 0, Best},
+		{"best draw", Draw, Draw, 0, Best},
+		{"best loss", LossIn(4), LossIn(4), 0, Best},
+		{"delayed win", WinIn(2), WinIn(4), 0.125, Inaccuracy},
+		{"faster loss", LossIn(4), LossIn(2), 0.125, Inaccuracy},
+		{"win to draw", WinIn(2), Draw, 0.5, Mistake},
+		{"draw to loss", Draw, LossIn(3), 0.5, Mistake},
+		{"win to loss", WinIn(1), LossIn(2), 1, Blunder},
+		{"lower score", Estimate(50), Estimate(-50), 0.5, Mistake},
+	}
+
+	for _, test := range tests {
+		penalty := Penalty(test.best, test.move)
+		if penalty != test.penalty {
+			t.Errorf("%s: Penalty(%s, %s) = %v, want %v", test.name, test.best, test.move, penalty, test.penalty)
+		}
+
+		if grade := GradePenalty(penalty); grade != test.grade {
+			t.Errorf("%s: GradePenalty(%v) = %s, want %s", test.name, penalty, grade, test.grade)
+		}
+	}
+}
+
+func TestDelayPenaltyBound(t *testing.T) {
+	// delaying a win or speeding up a loss is never as bad as changing the
+	// outcome, however long the game is
+	for _, d := range []int{2, 10, 1000} {
+		if p := Penalty(WinIn(1), WinIn(d)); p >= DelayPenalty || p >= Penalty(WinIn(1), Draw) {
+			t.Errorf("Penalty(WinIn(1), WinIn(%d)) = %v", d, p)
+		}
+
+		if p := Penalty(LossIn(d), LossIn(1)); p >= DelayPenalty || p >= Penalty(Draw, LossIn(1)) {
+			t.Errorf("Penalty(LossIn(%d), LossIn(1)) = %v", d, p)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluation
+
+import (
+	"math"
+
+	"laptudirm.com/x/wreck/pkg/board"
+)
+
+// Sample represents a position with a known exact evaluation, used to tune
+// the Weights of the heuristic evaluation.
+type Sample struct {
+	Position board.Board
+	Eval     Rel
+}
+
+// target returns the heuristic score which the Sample's position should
+// ideally have, which is 100 for a win, 0 for a draw and -100 for a loss.
+func (s Sample) target() float64 {
+	switch s.Eval.Outcome() {
+	case Won:
+		return 100
+	case Lost:
+		return -100
+	default:
+		return 0
+	}
+}
+
+// Tune fits the Weights of the heuristic evaluation to the given samples
+// using ridge regression, i.e, it finds the weights which minimize the sum
+// of squared differences between the heuristic scores and the targets of
+// the samples, plus lambda times the sum of the squared weights. Samples
+// of finished games are ignored, as they are evaluated exactly.
+func Tune(samples []Sample, lambda float64) Weights {
+	// normal equations (XᵀX + λI)w = Xᵀy
+	var a [NumFeatures][NumFeatures]float64
+	var y [NumFeatures]float64
+
+	for _, sample := range samples {
+		if sample.Position.State() != board.Unfinished {
+			continue
+		}
+
+		features := Features(sample.Position)
+		for i, fi := range features {
+			for j, fj := range features {
+				a[i][j] += fi * fj
+			}
+
+			y[i] += fi * sample.target()
+		}
+	}
+
+	for i := range a {
+		a[i][i] += lambda
+	}
+
+	return Weights(solve(a, y))
+}
+
+// solve solves the given system of linear equations using gaussian
+// elimination with partial pivoting. Unknowns which are not determined by
+// the system are set to zero.
+func solve(a [NumFeatures][NumFeatures]float64, y [NumFeatures]float64) [NumFeatures]float64 {
+	const n = int(NumFeatures)
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+
+		a[col], a[pivot] = a[pivot], a[col]
+		y[col], y[pivot] = y[pivot], y[col]
+
+		if math.Abs(a[col][col]) < 1e-12 {
+			continue
+		}
+
+		for row := col + 1; row < n; row++ {
+			factor := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= factor * a[col][k]
+			}
+
+			y[row] -= factor * y[col]
+		}
+	}
+
+	var x [NumFeatures]float64
+	for row := n - 1; row >= 0; row-- {
+		if math.Abs(a[row][row]) < 1e-12 {
+			continue
+		}
+
+		sum := y[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+
+		x[row] = sum / a[row][row]
+	}
+
+	return x
+}
+
+// Fit represents how well a set of Weights fits a set of samples.
+type Fit struct {
+	Samples  int     // number of unfinished positions
+	RMSE     float64 // root mean squared error from the targets
+	Accuracy float64 // fraction of positions whose result is predicted
+}
+
+// Fit measures how well the Weights fit the given samples. The result of
+// a position is predicted by rounding it's heuristic score to the nearest
+// of the targets for a win, draw or a loss. Samples of finished games are
+// ignored.
+func (w Weights) Fit(samples []Sample) Fit {
+	var fit Fit
+	var squares float64
+	var correct int
+
+	for _, sample := range samples {
+		if sample.Position.State() != board.Unfinished {
+			continue
+		}
+
+		fit.Samples++
+
+		score := float64(w.Evaluate(sample.Position))
+		target := sample.target()
+		squares += (score - target) * (score - target)
+
+		predicted := math.Max(-100, math.Min(100, 100*math.Round(score/100)))
+		if predicted == target {
+			correct++
+		}
+	}
+
+	if fit.Samples > 0 {
+		fit.RMSE = math.Sqrt(squares / float64(fit.Samples))
+		fit.Accuracy = float64(correct) / float64(fit.Samples)
+	}
+
+	return fit
+}
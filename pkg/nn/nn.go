@@ -0,0 +1,239 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nn implements a small multilayer perceptron which learns to
+// evaluate tic tac toe positions from the labels of the tablebase, so that
+// learned evaluation can be experimented with on boards where a tablebase
+// doesn't exist. It only depends on the standard library, and is meant to
+// be trained on a CPU.
+//
+// The network takes the feature planes of a position from the dataset
+// package as it's input, and has a single hidden layer with a tanh
+// activation. It's output is a probability distribution over the results
+// of the position relative to the player to move, i.e, win, draw and loss.
+package nn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/dataset"
+)
+
+// Inputs is the number of inputs of a Network, which are the cells marked
+// by player x, the cells marked by player o, and whose turn it is.
+const Inputs = 9 + 9 + 1
+
+// Outputs is the number of outputs of a Network, which are the
+// probabilities of a win, a draw and a loss for the player to move.
+const Outputs = 3
+
+// indices of the outputs of a Network
+const (
+	win = iota
+	draw
+	loss
+)
+
+// Network is a multilayer perceptron with a single hidden layer.
+type Network struct {
+	hidden int
+
+	w1 []float64 // hidden x Inputs weights of the hidden layer
+	b1 []float64 // biases of the hidden layer
+	w2 []float64 // Outputs x hidden weights of the output layer
+	b2 []float64 // biases of the output layer
+}
+
+// New creates a new Network with the given number of hidden neurons, whose
+// weights are randomly initialized using the given seed.
+func New(hidden int, seed int64) *Network {
+	n := newNetwork(hidden)
+	r := rand.New(rand.NewSource(seed))
+
+	// xavier initialization
+	for i := range n.w1 {
+		n.w1[i] = r.NormFloat64() * math.Sqrt(1/float64(Inputs))
+	}
+
+	for i := range n.w2 {
+		n.w2[i] = r.NormFloat64() * math.Sqrt(1/float64(hidden))
+	}
+
+	return n
+}
+
+// newNetwork creates a new Network with the given number of hidden neurons
+// whose weights are all zero.
+func newNetwork(hidden int) *Network {
+	return &Network{
+		hidden: hidden,
+		w1:     make([]float64, hidden*Inputs),
+		b1:     make([]float64, hidden),
+		w2:     make([]float64, Outputs*hidden),
+		b2:     make([]float64, Outputs),
+	}
+}
+
+// Hidden returns the number of hidden neurons of the Network.
+func (n *Network) Hidden() int {
+	return n.hidden
+}
+
+// Evaluate returns the probabilities of a win, a draw and a loss for the
+// player to move in the given position, according to the Network.
+func (n *Network) Evaluate(b board.Board) (win, draw, loss float64) {
+	out := n.forward(input(b), make([]float64, n.hidden))
+	return out[0], out[1], out[2]
+}
+
+// Score returns the expected result of the given position for the player
+// to move, according to the Network, in the range [-1, 1], where 1 is a
+// certain win and -1 is a certain loss.
+func (n *Network) Score(b board.Board) float64 {
+	win, _, loss := n.Evaluate(b)
+	return win - loss
+}
+
+// input converts the given position into the inputs of a Network.
+func input(b board.Board) [Inputs]float64 {
+	x, o, turn := dataset.Features(b)
+	return recordInput(dataset.Record{X: x, O: o, Turn: turn})
+}
+
+// recordInput converts the features of the given record into the inputs
+// of a Network.
+func recordInput(r dataset.Record) [Inputs]float64 {
+	var in [Inputs]float64
+	for i := 0; i < 9; i++ {
+		in[i] = float64(r.X[i])
+		in[9+i] = float64(r.O[i])
+	}
+
+	in[18] = float64(r.Turn)
+	return in
+}
+
+// forward calculates the outputs of the Network for the given inputs. The
+// activations of the hidden layer are stored in the given slice.
+func (n *Network) forward(in [Inputs]float64, hidden []float64) [Outputs]float64 {
+	for i := 0; i < n.hidden; i++ {
+		sum := n.b1[i]
+		for j, x := range in {
+			sum += n.w1[i*Inputs+j] * x
+		}
+
+		hidden[i] = math.Tanh(sum)
+	}
+
+	var out [Outputs]float64
+	for i := range out {
+		sum := n.b2[i]
+		for j, h := range hidden {
+			sum += n.w2[i*n.hidden+j] * h
+		}
+
+		out[i] = sum
+	}
+
+	return softmax(out)
+}
+
+// softmax converts the given logits into a probability distribution.
+func softmax(logits [Outputs]float64) [Outputs]float64 {
+	max := logits[0]
+	for _, l := range logits[1:] {
+		max = math.Max(max, l)
+	}
+
+	var sum float64
+	for i, l := range logits {
+		logits[i] = math.Exp(l - max)
+		sum += logits[i]
+	}
+
+	for i := range logits {
+		logits[i] /= sum
+	}
+
+	return logits
+}
+
+// label converts the result of a record into the index of the output of a
+// Network which represents it.
+func label(r dataset.Record) int {
+	switch {
+	case r.WDL > 0:
+		return win
+	case r.WDL < 0:
+		return loss
+	default:
+		return draw
+	}
+}
+
+// magic identifies a file containing the weights of a Network.
+const magic = "WNN1"
+
+// Save writes the weights of the Network to the given writer, in a format
+// which can be read back using Load. All the numbers are stored in little
+// endian byte order.
+func (n *Network) Save(w io.Writer) error {
+	if _, err := io.WriteString(w, magic); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(n.hidden)); err != nil {
+		return err
+	}
+
+	for _, weights := range [][]float64{n.w1, n.b1, n.w2, n.b2} {
+		if err := binary.Write(w, binary.LittleEndian, weights); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Load reads the weights of a Network, which have been written by Save,
+// from the given reader.
+func Load(r io.Reader) (*Network, error) {
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, header); err != nil || string(header) != magic {
+		return nil, fmt.Errorf("nn: invalid weights file")
+	}
+
+	var hidden uint32
+	if err := binary.Read(r, binary.LittleEndian, &hidden); err != nil {
+		return nil, fmt.Errorf("nn: invalid weights file")
+	}
+
+	// sanity check the size to avoid large allocations on corrupt files
+	if hidden == 0 || hidden > 1<<16 {
+		return nil, fmt.Errorf("nn: invalid hidden layer size %d", hidden)
+	}
+
+	n := newNetwork(int(hidden))
+	for _, weights := range [][]float64{n.w1, n.b1, n.w2, n.b2} {
+		if err := binary.Read(r, binary.LittleEndian, weights); err != nil {
+			return nil, fmt.Errorf("nn: invalid weights file")
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,121 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nn
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/dataset"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func TestSaveLoad(t *testing.T) {
+	n := New(8, 1)
+
+	var buffer bytes.Buffer
+	if err := n.Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(n, loaded) {
+		t.Error("loaded network differs from the saved one")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := New(4, 1).Save(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := buffer.Bytes()
+
+	// every truncated file is invalid
+	for length := 0; length < len(valid); length++ {
+		if _, err := Load(bytes.NewReader(valid[:length])); err == nil {
+			t.Fatalf("Load() accepted a file truncated to %d of %d bytes", length, len(valid))
+		}
+	}
+
+	corrupt := func(offset int, data ...byte) []byte {
+		file := append([]byte{}, valid...)
+		copy(file[offset:], data)
+		return file
+	}
+
+	tests := map[string][]byte{
+		"magic":         corrupt(0, 'X'),
+		"empty hidden":  corrupt(4, 0, 0, 0, 0),
+		"large hidden":  corrupt(4, 0xff, 0xff, 0xff, 0xff),
+		"larger hidden": corrupt(4, 5), // the weights end too early
+	}
+
+	for name, file := range tests {
+		if _, err := Load(bytes.NewReader(file)); err == nil {
+			t.Errorf("Load() accepted a file with a corrupt %s", name)
+		}
+	}
+}
+
+func TestTrain(t *testing.T) {
+	var records []dataset.Record
+	for _, data := range tablebase.Generate().Positions() {
+		records = append(records, dataset.NewRecord(data.Position(), data.AbsEval(), data.BestMoves()))
+	}
+
+	config := Config{
+		Epochs:       5,
+		BatchSize:    32,
+		LearningRate: 0.05,
+		Momentum:     0.9,
+		Seed:         1,
+	}
+
+	train := func() (*Network, []float64) {
+		n := New(16, 1)
+
+		var losses []float64
+		n.Train(records, config, func(epoch int, loss float64) {
+			if epoch != len(losses)+1 {
+				t.Fatalf("epoch %d reported after %d epochs", epoch, len(losses))
+			}
+
+			losses = append(losses, loss)
+		})
+
+		return n, losses
+	}
+
+	n, losses := train()
+	if len(losses) != config.Epochs {
+		t.Fatalf("got the loss of %d epochs, want %d", len(losses), config.Epochs)
+	}
+
+	if losses[len(losses)-1] >= losses[0] {
+		t.Errorf("loss didn't decrease: %v", losses)
+	}
+
+	// training is deterministic
+	m, again := train()
+	if !reflect.DeepEqual(losses, again) || !reflect.DeepEqual(n, m) {
+		t.Errorf("training with the same seed differs: %v, %v", losses, again)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nn
+
+import (
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/dataset"
+	"laptudirm.com/x/wreck/pkg/engine"
+)
+
+// player is an engine.Player which chooses it's moves using a Network.
+type player struct {
+	network *Network
+}
+
+// Player returns an engine.Player which plays the move leading to the
+// position with the best score for it according to the Network. Moves
+// which win the game immediately are always preferred.
+func (n *Network) Player() engine.Player {
+	return player{network: n}
+}
+
+// Name returns the name of the Player.
+func (p player) Name() string {
+	return "nn"
+}
+
+// Move returns the move chosen by the Network in the given position.
+func (p player) Move(b board.Board) board.Move {
+	var best board.Move
+	bestScore := -2.0
+
+	for _, move := range b.ValidMoves() {
+		child := b
+		child.Play(move)
+
+		var score float64
+		switch child.State() {
+		case board.Unfinished:
+			// score of the child is relative to the opponent
+			score = -p.network.Score(child)
+		case board.GameDrawn:
+			score = 0
+		default:
+			// the move has won the game
+			return move
+		}
+
+		if score > bestScore {
+			best, bestScore = move, score
+		}
+	}
+
+	return best
+}
+
+// Report represents the accuracy of a Network on a set of records.
+type Report struct {
+	Positions int // number of positions tested
+	Correct   int // positions whose result was predicted correctly
+
+	Moves     int // number of unfinished positions tested
+	BestMoves int // unfinished positions where a best move was played
+}
+
+// ResultAccuracy returns the fraction of positions whose result was
+// predicted correctly.
+func (r Report) ResultAccuracy() float64 {
+	if r.Positions == 0 {
+		return 0
+	}
+
+	return float64(r.Correct) / float64(r.Positions)
+}
+
+// MoveAccuracy returns the fraction of unfinished positions where the
+// Network's Player played one of the best moves.
+func (r Report) MoveAccuracy() float64 {
+	if r.Moves == 0 {
+		return 0
+	}
+
+	return float64(r.BestMoves) / float64(r.Moves)
+}
+
+// Test tests the Network against the labels of the given records, which
+// are usually generated from the tablebase, and reports it's accuracy.
+func (n *Network) Test(records []dataset.Record) Report {
+	var report Report
+
+	p := n.Player()
+	hidden := make([]float64, n.hidden)
+
+	for _, record := range records {
+		report.Positions++
+
+		out := n.forward(recordInput(record), hidden)
+		predicted := win
+		for i := range out {
+			if out[i] > out[predicted] {
+				predicted = i
+			}
+		}
+
+		if predicted == label(record) {
+			report.Correct++
+		}
+
+		if record.Position.State() == board.Unfinished {
+			report.Moves++
+			if record.Policy[p.Move(record.Position)-1] == 1 {
+				report.BestMoves++
+			}
+		}
+	}
+
+	return report
+}
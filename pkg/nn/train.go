@@ -0,0 +1,167 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nn
+
+import (
+	"math"
+	"math/rand"
+
+	"laptudirm.com/x/wreck/pkg/dataset"
+)
+
+// Config represents the hyperparameters used to train a Network.
+type Config struct {
+	Epochs       int     // number of passes over the training records
+	BatchSize    int     // number of records in each gradient step
+	LearningRate float64 // size of each gradient step
+	Momentum     float64 // fraction of the previous step added to a step
+	Seed         int64   // seed used to shuffle the records
+}
+
+// DefaultConfig is a Config which trains a Network to fit the labels of
+// the tablebase reasonably well in a few seconds.
+var DefaultConfig = Config{
+	Epochs:       300,
+	BatchSize:    32,
+	LearningRate: 0.05,
+	Momentum:     0.9,
+	Seed:         1,
+}
+
+// Train trains the Network on the given records using mini-batch gradient
+// descent with momentum, minimizing the cross-entropy between the outputs
+// of the Network and the results of the records. If progress is not nil,
+// it is called after each epoch with the epoch's number, starting from 1,
+// and the average loss over the epoch.
+func (n *Network) Train(records []dataset.Record, config Config, progress func(epoch int, loss float64)) {
+	if len(records) == 0 {
+		return
+	}
+
+	batchSize := config.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	r := rand.New(rand.NewSource(config.Seed))
+
+	inputs := make([][Inputs]float64, len(records))
+	labels := make([]int, len(records))
+	for i, record := range records {
+		inputs[i] = recordInput(record)
+		labels[i] = label(record)
+	}
+
+	grad := newNetwork(n.hidden)     // gradient of the current batch
+	velocity := newNetwork(n.hidden) // momentum of the previous steps
+
+	hidden := make([]float64, n.hidden)
+	delta := make([]float64, n.hidden)
+
+	order := r.Perm(len(records))
+	for epoch := 1; epoch <= config.Epochs; epoch++ {
+		r.Shuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+
+		var total float64
+		for start := 0; start < len(order); start += batchSize {
+			end := start + batchSize
+			if end > len(order) {
+				end = len(order)
+			}
+
+			grad.zero()
+			for _, i := range order[start:end] {
+				total += n.backward(inputs[i], labels[i], grad, hidden, delta)
+			}
+
+			// gradient step, averaged over the batch
+			rate := config.LearningRate / float64(end-start)
+			n.step(grad, velocity, rate, config.Momentum)
+		}
+
+		if progress != nil {
+			progress(epoch, total/float64(len(records)))
+		}
+	}
+}
+
+// backward adds the gradient of the loss of the given example to the given
+// gradient, and returns the loss. The hidden and delta slices are used as
+// scratch space for the activations and gradients of the hidden layer.
+func (n *Network) backward(in [Inputs]float64, label int, grad *Network, hidden, delta []float64) float64 {
+	out := n.forward(in, hidden)
+
+	// gradient of the cross-entropy with respect to the logits
+	for i := range out {
+		d := out[i]
+		if i == label {
+			d--
+		}
+
+		grad.b2[i] += d
+		for j, h := range hidden {
+			grad.w2[i*n.hidden+j] += d * h
+		}
+	}
+
+	for j, h := range hidden {
+		var sum float64
+		for i := range out {
+			d := out[i]
+			if i == label {
+				d--
+			}
+
+			sum += n.w2[i*n.hidden+j] * d
+		}
+
+		// derivative of tanh
+		delta[j] = sum * (1 - h*h)
+	}
+
+	for j, d := range delta {
+		grad.b1[j] += d
+		for k, x := range in {
+			grad.w1[j*Inputs+k] += d * x
+		}
+	}
+
+	return -math.Log(math.Max(out[label], 1e-12))
+}
+
+// step updates the weights of the Network with the given gradient, using
+// the given learning rate and momentum.
+func (n *Network) step(grad, velocity *Network, rate, momentum float64) {
+	weights := [][]float64{n.w1, n.b1, n.w2, n.b2}
+	grads := [][]float64{grad.w1, grad.b1, grad.w2, grad.b2}
+	velocities := [][]float64{velocity.w1, velocity.b1, velocity.w2, velocity.b2}
+
+	for i, w := range weights {
+		for j := range w {
+			velocities[i][j] = momentum*velocities[i][j] - rate*grads[i][j]
+			w[j] += velocities[i][j]
+		}
+	}
+}
+
+// zero sets all the weights of the Network to zero.
+func (n *Network) zero() {
+	for _, weights := range [][]float64{n.w1, n.b1, n.w2, n.b2} {
+		for i := range weights {
+			weights[i] = 0
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sample implements the generation of random legal positions and
+// random games, for use as test fixtures and datasets. All the randomness
+// comes from a seeded source, so the results are reproducible.
+package sample
+
+import (
+	"fmt"
+	"math/rand"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+// Oracle provides perfect knowledge about positions, like a tablebase.
+type Oracle interface {
+	// Eval returns the absolute evaluation of the given position. It
+	// returns false as the second argument if the position is unknown.
+	Eval(board.Board) (evaluation.Abs, bool)
+
+	// BestMoves returns the best moves in the given position.
+	BestMoves(board.Board) []board.Move
+}
+
+// Filter reports whether a position should be included in a sample.
+type Filter func(board.Board) bool
+
+// WithEval returns a Filter which only includes the positions which have
+// the given evaluation according to the given Oracle.
+func WithEval(oracle Oracle, eval evaluation.Abs) Filter {
+	return func(b board.Board) bool {
+		e, found := oracle.Eval(b)
+		return found && e == eval
+	}
+}
+
+// WithOutcome returns a Filter which only includes the positions where the
+// game ends in the given state with perfect play, according to the given
+// Oracle. The state should be one of GameDrawn, PlayerXWon and PlayerOWon.
+func WithOutcome(oracle Oracle, state board.State) Filter {
+	return func(b board.Board) bool {
+		e, found := oracle.Eval(b)
+		switch {
+		case !found:
+			return false
+		case e.Outcome() == evaluation.Won:
+			return state == board.PlayerXWon
+		case e.Outcome() == evaluation.Lost:
+			return state == board.PlayerOWon
+		case e.Outcome() == evaluation.Drawn:
+			return state == board.GameDrawn
+		default:
+			return false
+		}
+	}
+}
+
+// Generator generates random positions and games.
+type Generator struct {
+	rand *rand.Rand
+}
+
+// New creates a new Generator which uses the given seed.
+func New(seed int64) *Generator {
+	return &Generator{
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Positions returns the given number of positions, sampled uniformly at
+// random with replacement from the legal positions with the given move
+// number which are included by the filter. If the move number is negative,
+// positions with any move number are sampled, and if the filter is nil,
+// all the positions are included. An error is returned if the count is
+// negative or if no position matches.
+func (g *Generator) Positions(count, ply int, filter Filter) ([]board.Board, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("sample: invalid count %d", count)
+	}
+
+	// collect the ranks of the positions which can be sampled
+	start, end := uint32(0), board.Positions()
+	if ply >= 0 {
+		start = board.Rank(board.UnrankPly(ply, 0))
+		end = start + board.PlyPositions(ply)
+	}
+
+	var ranks []uint32
+	for rank := start; rank < end; rank++ {
+		if filter == nil || filter(board.Unrank(rank)) {
+			ranks = append(ranks, rank)
+		}
+	}
+
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("sample: no positions match the given constraints")
+	}
+
+	positions := make([]board.Board, count)
+	for i := range positions {
+		positions[i] = board.Unrank(ranks[g.rand.Intn(len(ranks))])
+	}
+
+	return positions, nil
+}
+
+// Game plays a random game from the given position and returns the moves
+// which were played. With probability epsilon, a move is chosen uniformly
+// at random from the valid moves, and otherwise from the best moves given
+// by the Oracle. An epsilon of 1 or a nil Oracle results in uniformly
+// random games.
+func (g *Generator) Game(start board.Board, oracle Oracle, epsilon float64) []board.Move {
+	var moves []board.Move
+	for b := start; b.State() == board.Unfinished; {
+		candidates := b.ValidMoves()
+		if oracle != nil && g.rand.Float64() >= epsilon {
+			if best := oracle.BestMoves(b); len(best) > 0 {
+				candidates = best
+			}
+		}
+
+		move := candidates[g.rand.Intn(len(candidates))]
+		b.Play(move)
+		moves = append(moves, move)
+	}
+
+	return moves
+}
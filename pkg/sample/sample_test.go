@@ -0,0 +1,134 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sample
+
+import (
+	"reflect"
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+	"laptudirm.com/x/wreck/pkg/tablebase"
+)
+
+func TestPositionsReproducible(t *testing.T) {
+	for _, seed := range []int64{0, 1, 42} {
+		a, err := New(seed).Positions(50, -1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := New(seed).Positions(50, -1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("seed %d: positions differ between generators", seed)
+		}
+	}
+}
+
+func TestGameReproducible(t *testing.T) {
+	table := tablebase.Generate()
+	for _, seed := range []int64{0, 1, 42} {
+		var start board.Board // zero value is starting board
+		a := New(seed).Game(start, table, 0.5)
+		b := New(seed).Game(start, table, 0.5)
+
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("seed %d: games %v and %v differ", seed, a, b)
+		}
+	}
+}
+
+func TestPositionsPly(t *testing.T) {
+	for ply := 0; ply <= 9; ply++ {
+		positions, err := New(1).Positions(20, ply, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(positions) != 20 {
+			t.Fatalf("ply %d: got %d positions, want 20", ply, len(positions))
+		}
+
+		for _, position := range positions {
+			if got := position.MoveNumber(); got != ply {
+				t.Errorf("ply %d: %s has move number %d", ply, position.PositionString(), got)
+			}
+		}
+	}
+}
+
+func TestWithEval(t *testing.T) {
+	table := tablebase.Generate()
+	for _, s := range []string{"+W3", "-W2", "±00"} {
+		eval, err := evaluation.ParseAbs(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		positions, err := New(1).Positions(20, -1, WithEval(table, eval))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, position := range positions {
+			if got, _ := table.Eval(position); got != eval {
+				t.Errorf("%s: %s has evaluation %s", s, position.PositionString(), got)
+			}
+		}
+	}
+}
+
+func TestWithOutcome(t *testing.T) {
+	table := tablebase.Generate()
+	tests := []struct {
+		state   board.State
+		outcome evaluation.Outcome
+	}{
+		{board.PlayerXWon, evaluation.Won},
+		{board.PlayerOWon, evaluation.Lost},
+		{board.GameDrawn, evaluation.Drawn},
+	}
+
+	for _, test := range tests {
+		positions, err := New(1).Positions(50, 4, WithOutcome(table, test.state))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, position := range positions {
+			if position.MoveNumber() != 4 {
+				t.Errorf("%s: %s has move number %d", test.state, position.PositionString(), position.MoveNumber())
+			}
+
+			if got, _ := table.Eval(position); got.Outcome() != test.outcome {
+				t.Errorf("%s: %s has evaluation %s", test.state, position.PositionString(), got)
+			}
+		}
+	}
+}
+
+func TestPositionsErrors(t *testing.T) {
+	if _, err := New(1).Positions(-1, -1, nil); err == nil {
+		t.Error("Positions(-1) didn't fail")
+	}
+
+	never := func(board.Board) bool { return false }
+	if _, err := New(1).Positions(1, -1, never); err == nil {
+		t.Error("Positions() didn't fail when no position matches")
+	}
+}
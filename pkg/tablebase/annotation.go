@@ -0,0 +1,213 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablebase
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+// Annotation represents a position annotated with it's evaluation and best
+// moves, like a line of an EPD file in chess. Annotations are used to store
+// analysis and test fixtures outside of the tablebase.
+type Annotation struct {
+	Position  board.Board    // annotated position
+	Eval      evaluation.Abs // absolute evaluation of the position
+	BestMoves []board.Move   // best moves in the position
+}
+
+// String converts an Annotation to it's string representation, which
+// consists of the position string, the evaluation, and a comma separated
+// list of the best moves, separated by spaces. Positions without any valid
+// moves have a - in place of the best moves.
+func (a Annotation) String() string {
+	moves := "-"
+	if len(a.BestMoves) > 0 {
+		list := make([]string, len(a.BestMoves))
+		for i, move := range a.BestMoves {
+			list[i] = fmt.Sprint(move)
+		}
+
+		moves = strings.Join(list, ",")
+	}
+
+	return fmt.Sprintf("%s %s %s", a.Position.PositionString(), a.Eval, moves)
+}
+
+// AnnotationError is the error reported when an invalid annotation string
+// is provided to ParseAnnotation.
+type AnnotationError struct {
+	annotation string
+}
+
+func (e AnnotationError) Error() string {
+	return fmt.Sprintf("tablebase: invalid annotation string %#v", e.annotation)
+}
+
+// ParseAnnotation parses an annotation from it's string representation,
+// which is the format produced by Annotation.String. The evaluation may be
+// in any format accepted by evaluation.ParseAbs. It returns an
+// AnnotationError if the given string is invalid.
+func ParseAnnotation(s string) (Annotation, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 {
+		return Annotation{}, AnnotationError{s}
+	}
+
+	position, err := board.New(fields[0])
+	if err != nil {
+		return Annotation{}, AnnotationError{s}
+	}
+
+	eval, err := evaluation.ParseAbs(fields[1])
+	if err != nil {
+		return Annotation{}, AnnotationError{s}
+	}
+
+	var moves []board.Move
+	if fields[2] != "-" {
+		var valid bool
+		if moves, valid = parseMoveList(position, fields[2]); !valid {
+			return Annotation{}, AnnotationError{s}
+		}
+	}
+
+	return Annotation{
+		Position:  position,
+		Eval:      eval,
+		BestMoves: moves,
+	}, nil
+}
+
+// ReadAnnotations reads annotations from the given reader, where each line
+// contains a single annotation in the format produced by Annotation.String.
+// Empty lines and lines starting with a # are ignored.
+func ReadAnnotations(r io.Reader) ([]Annotation, error) {
+	var annotations []Annotation
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		annotation, err := ParseAnnotation(line)
+		if err != nil {
+			return nil, err
+		}
+
+		annotations = append(annotations, annotation)
+	}
+
+	return annotations, scanner.Err()
+}
+
+// WriteAnnotations writes the given annotations to the given writer, one
+// on each line, in the format read by ReadAnnotations.
+func WriteAnnotations(w io.Writer, annotations []Annotation) error {
+	buffer := bufio.NewWriter(w)
+	for _, annotation := range annotations {
+		fmt.Fprintln(buffer, annotation)
+	}
+
+	return buffer.Flush()
+}
+
+// Annotations returns an Annotation for every position in the tablebase,
+// ordered by their move number.
+func (t *Table) Annotations() []Annotation {
+	var annotations []Annotation
+	for _, data := range t.Positions() {
+		annotations = append(annotations, data.Annotation())
+	}
+
+	return annotations
+}
+
+// Annotation returns the Annotation of the position represented by the
+// boardData.
+func (b boardData) Annotation() Annotation {
+	return Annotation{
+		Position:  b.board,
+		Eval:      b.eval,
+		BestMoves: b.BestMoves(),
+	}
+}
+
+// Check checks the given Annotation against the tablebase, and returns an
+// error describing the difference if the evaluation or the best moves of
+// the position don't match. The best moves may be in any order.
+func (t *Table) Check(a Annotation) error {
+	data, found := t.Search(a.Position)
+	if !found {
+		return fmt.Errorf("tablebase: %s: position not legal", a.Position.PositionString())
+	}
+
+	got := data.Annotation()
+	if got.Eval != a.Eval {
+		return fmt.Errorf("tablebase: %s: evaluation is %s, expected %s", a.Position.PositionString(), got.Eval, a.Eval)
+	}
+
+	var expected, actual board.Bitboard
+	for _, move := range a.BestMoves {
+		expected.Set(move)
+	}
+
+	for _, move := range got.BestMoves {
+		actual.Set(move)
+	}
+
+	if expected != actual {
+		return fmt.Errorf("tablebase: %s: best moves are %s, expected %s",
+			a.Position.PositionString(), got.movesString(), a.movesString())
+	}
+
+	return nil
+}
+
+// CheckAnnotations checks the given annotations against the tablebase,
+// like a golden file, and returns an error for each annotation which
+// doesn't match and for each position of the tablebase which isn't
+// annotated. It returns nil if the annotations cover the whole tablebase.
+func (t *Table) CheckAnnotations(annotations []Annotation) []error {
+	var errs []error
+	checked := make(map[board.Board]bool)
+
+	for _, annotation := range annotations {
+		checked[annotation.Position] = true
+		if err := t.Check(annotation); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, data := range t.Positions() {
+		if !checked[data.Position()] {
+			errs = append(errs, fmt.Errorf("tablebase: %s: missing from golden file", data.Position().PositionString()))
+		}
+	}
+
+	return errs
+}
+
+// movesString returns the best moves field of the Annotation's string.
+func (a Annotation) movesString() string {
+	s := a.String()
+	return s[strings.LastIndexByte(s, ' ')+1:]
+}
@@ -0,0 +1,122 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablebase
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+func TestGolden(t *testing.T) {
+	file, err := os.Open("testdata/tablebase.epd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	annotations, err := ReadAnnotations(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, err := range Generate().CheckAnnotations(annotations) {
+		t.Error(err)
+	}
+}
+
+func TestCheckAnnotations(t *testing.T) {
+	table := Generate()
+	annotations := table.Annotations()
+
+	if errs := table.CheckAnnotations(annotations); errs != nil {
+		t.Fatalf("tablebase doesn't match it's own annotations: %v", errs)
+	}
+
+	// change an evaluation, and leave out the last position
+	annotations[0].Eval = evaluation.Abs(evaluation.WinIn(9))
+	annotations = annotations[:len(annotations)-1]
+
+	if errs := table.CheckAnnotations(annotations); len(errs) != 2 {
+		t.Errorf("got errors %v, want an evaluation and a missing position", errs)
+	}
+}
+
+func TestParseAnnotation(t *testing.T) {
+	for _, s := range []string{
+		"......... ±00 1,2,3,4,5,6,7,8,9",
+		"x........ ±00 5",
+		"xo.x..... +W3 7",
+		"xxxoo.... +W1 -",
+	} {
+		annotation, err := ParseAnnotation(s)
+		if err != nil {
+			t.Errorf("ParseAnnotation(%#v): %v", s, err)
+			continue
+		}
+
+		if annotation.String() != s {
+			t.Errorf("ParseAnnotation(%#v).String() = %#v", s, annotation.String())
+		}
+	}
+
+	for _, s := range []string{
+		"", "x........", "x........ ±00", "x........ W3 5", "xxxxxxxxx ±00 -",
+		"x........ ±00 0", "x........ ±00 1",
+	} {
+		if _, err := ParseAnnotation(s); err == nil {
+			t.Errorf("ParseAnnotation(%#v) didn't fail", s)
+		}
+	}
+}
+
+func TestReadAnnotations(t *testing.T) {
+	input := "# comment\n\nx........ =00 5\n"
+	annotations, err := ReadAnnotations(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(annotations) != 1 || annotations[0].String() != "x........ ±00 5" {
+		t.Errorf("ReadAnnotations() = %v", annotations)
+	}
+}
+
+func TestParseAlgebraicMoves(t *testing.T) {
+	annotation, err := ParseAnnotation("x........ ±00 b2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if annotation.String() != "x........ ±00 5" {
+		t.Errorf("ParseAnnotation() = %#v", annotation.String())
+	}
+
+	puzzle, err := ParsePuzzle("xoxo..... fork b2,C1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if puzzle.String() != "xoxo..... fork 5,9" {
+		t.Errorf("ParsePuzzle() = %#v", puzzle.String())
+	}
+
+	for _, s := range []string{"xoxo..... fork a3", "xoxo..... fork d1", "xoxo..... fork 5,"} {
+		if _, err := ParsePuzzle(s); err == nil {
+			t.Errorf("ParsePuzzle(%#v) didn't fail", s)
+		}
+	}
+}
@@ -20,13 +20,20 @@ import (
 
 // Generate creates and evaluates all the boards from the default tic tac
 // toe starting position. It generates the entire tablebase.
-func Generate() tablebase {
-	var table tablebase
+func Generate() *Table {
 	var board board.Board // zero value is starting board
+	return GenerateFrom(board)
+}
+
+// GenerateFrom creates and evaluates all the boards reachable from the
+// given root position, like handicap starts or puzzle positions which
+// can't be reached from the default starting position.
+func GenerateFrom(root board.Board) *Table {
+	var table Table
 
-	// generate boards from starting position
-	table.generateBoardsFrom(board)
-	return table
+	// generate boards from root position
+	table.generateBoardsFrom(root)
+	return &table
 }
 
 // generateBoardsFrom generates the children Boards for a given Board,
@@ -35,49 +42,27 @@ func Generate() tablebase {
 // from the given position. The generated boards are given an evaluation
 // and stored in the tablebase. It returns the index of the given board in
 // the tablebase and boards evaluation relative to the player.
-func (t *tablebase) generateBoardsFrom(b board.Board) (boardIndex, evaluation.Rel) {
+func (t *Table) generateBoardsFrom(b board.Board) (boardIndex, evaluation.Rel) {
 	// check if Board has already been generated
 	if index, found := t.indexOf(b); found {
 		eval := evaluation.ToRel(index.fetch().eval, b)
 		return index, eval
 	}
 
-	var moves moveMap          // map of valid moves to boards
-	eval := evaluation.LossIn1 // uses lowest possible eval
-
-	// generate evaluation from game state
-	switch b.State() {
-	case board.Unfinished:
-		validMoves := b.ValidMoves()
-
-		// generate and evaluate each valid move on the Board
-		for _, move := range validMoves {
-			newBoard := b       // create a copy
-			newBoard.Play(move) // play the move
-
-			nextIndex, nextEval := t.generateBoardsFrom(newBoard)
-			moves.add(move, nextIndex) // add move to moveMap
+	var moves moveMap // map of valid moves to boards
 
-			// flip the relative eval to current player's perspective
-			moveEval := evaluation.Flip(nextEval)
+	// generate and evaluate each valid move on the Board
+	for _, move := range b.ValidMoves() {
+		newBoard := b       // create a copy
+		newBoard.Play(move) // play the move
 
-			// update board evaluation
-			if moveEval > eval {
-				eval = moveEval
-			}
-		}
-
-	// game finished, no valid moves remain
-	// so hardcode evaluation depending on state
-	case board.PlayerXWon, board.PlayerOWon:
-		// relative evaluation of an immediate loss
-		eval = evaluation.LossIn1
-	case board.GameDrawn:
-		eval = evaluation.Draw
+		nextIndex, _ := t.generateBoardsFrom(newBoard)
+		moves.add(move, nextIndex) // add move to moveMap
 	}
 
 	// finalize move map by sorting according to eval
 	moves.finalize()
+	eval := evaluate(b, moves)
 
 	// push given board to tablebase
 	return t.pushBoard(boardData{
@@ -87,3 +72,31 @@ func (t *tablebase) generateBoardsFrom(b board.Board) (boardIndex, evaluation.Re
 		table:   t,
 	}), eval
 }
+
+// evaluate calculates the evaluation of the given Board relative to the
+// player to move, from the moveMap of the Board's children.
+func evaluate(b board.Board, moves moveMap) evaluation.Rel {
+	// generate evaluation from game state
+	switch b.State() {
+	case board.Unfinished:
+		eval := evaluation.LossIn1 // uses lowest possible eval
+
+		// update board evaluation from the moves, which are already
+		// evaluated from the current player's perspective
+		for _, entry := range moves.Moves() {
+			if entry.eval.Compare(eval) > 0 {
+				eval = entry.eval
+			}
+		}
+
+		return eval
+
+	// game finished, no valid moves remain
+	// so hardcode evaluation depending on state
+	case board.PlayerXWon, board.PlayerOWon:
+		// relative evaluation of an immediate loss
+		return evaluation.LossIn1
+	default:
+		return evaluation.Draw
+	}
+}
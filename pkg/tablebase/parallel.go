@@ -0,0 +1,194 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablebase
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+// GenerateParallel is a concurrent version of Generate, which distributes
+// the work of generating the tablebase among the given number of worker
+// goroutines. If workers is less than 1, the number of usable CPUs is used.
+//
+// Instead of a recursive search, the positions are first generated layer
+// by layer, where each layer contains the positions with the same move
+// number, and then evaluated layer by layer from the last one. The
+// resulting tablebase is equal to the one produced by Generate, though the
+// positions in a layer are stored in a different order.
+func GenerateParallel(workers int) *Table {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var root board.Board // zero value is starting board
+	layers := generateLayers(root, workers)
+
+	var table Table
+	table.storeLayers(layers, workers, func(b board.Board, _ int, moves moveMap) evaluation.Rel {
+		return evaluate(b, moves)
+	})
+
+	return &table
+}
+
+// storeLayers stores the positions of the given layers in the tablebase,
+// using the given function to evaluate each position from it's index in
+// it's layer and it's moveMap. The layers are stored from the last one,
+// since the moveMap of a position refers to the positions in the next
+// layer. The work is distributed among the given number of workers.
+func (t *Table) storeLayers(layers []*positionStore, workers int, eval func(b board.Board, i int, moves moveMap) evaluation.Rel) {
+	for move := len(layers) - 1; move >= 0; move-- {
+		layer := layers[move]
+		t.data[move] = make([]boardData, len(layer.boards))
+
+		var children *positionStore
+		if move+1 < len(layers) {
+			children = layers[move+1]
+		}
+
+		parallelize(len(layer.boards), workers, func(i int) {
+			b := layer.boards[i]
+
+			var moves moveMap
+			for _, move := range b.ValidMoves() {
+				newBoard := b
+				newBoard.Play(move)
+				moves.add(move, boardIndex{
+					move:  newBoard.MoveNumber(),
+					index: children.index[newBoard],
+					table: t,
+				})
+			}
+
+			moves.finalize()
+			rel := eval(b, i, moves)
+
+			t.data[move][i] = boardData{
+				board:   b,
+				eval:    evaluation.ToAbs(rel, b),
+				moveMap: moves,
+				table:   t,
+			}
+		})
+	}
+}
+
+// positionStore is a thread-safe set of positions with the same move
+// number, which remembers the index of each position.
+type positionStore struct {
+	sync.Mutex
+
+	boards []board.Board
+	index  map[board.Board]int
+}
+
+// add adds the given positions to the store, ignoring duplicates.
+func (s *positionStore) add(boards []board.Board) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, b := range boards {
+		if _, found := s.index[b]; !found {
+			s.index[b] = len(s.boards)
+			s.boards = append(s.boards, b)
+		}
+	}
+}
+
+// sort sorts the positions in the store by their position strings, so that
+// the order doesn't depend on the scheduling of the workers.
+func (s *positionStore) sort() {
+	sort.Slice(s.boards, func(i, j int) bool {
+		return s.boards[i].PositionString() < s.boards[j].PositionString()
+	})
+
+	for i, b := range s.boards {
+		s.index[b] = i
+	}
+}
+
+// generateLayers generates all the positions reachable from the given root
+// position, divided into layers by their move number. Layers before the
+// root's move number are empty.
+func generateLayers(root board.Board, workers int) []*positionStore {
+	layers := make([]*positionStore, root.MoveNumber()+1)
+	for i := range layers {
+		layers[i] = &positionStore{index: make(map[board.Board]int)}
+	}
+
+	layers[root.MoveNumber()].add([]board.Board{root})
+
+	for move := root.MoveNumber(); move < 9; move++ {
+		layer := layers[move]
+		next := &positionStore{index: make(map[board.Board]int)}
+
+		parallelize(len(layer.boards), workers, func(i int) {
+			b := layer.boards[i]
+
+			var children []board.Board
+			for _, move := range b.ValidMoves() {
+				newBoard := b
+				newBoard.Play(move)
+				children = append(children, newBoard)
+			}
+
+			next.add(children)
+		})
+
+		// all games have finished
+		if len(next.boards) == 0 {
+			break
+		}
+
+		next.sort()
+		layers = append(layers, next)
+	}
+
+	return layers
+}
+
+// parallelize calls the given function for each index from 0 to n-1,
+// distributing the calls among the given number of worker goroutines in
+// chunks of consecutive indices. It returns after all the calls return.
+func parallelize(n, workers int, f func(i int)) {
+	const chunkSize = 64
+
+	var wg sync.WaitGroup
+	var next int64 // start of the next unclaimed chunk
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				start := int(atomic.AddInt64(&next, chunkSize)) - chunkSize
+				if start >= n {
+					return
+				}
+
+				for i := start; i < start+chunkSize && i < n; i++ {
+					f(i)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
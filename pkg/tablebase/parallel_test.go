@@ -0,0 +1,47 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablebase
+
+import (
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+)
+
+func TestGenerateParallel(t *testing.T) {
+	serial := Generate()
+	for _, workers := range []int{0, 1, 2, 3, 8, 32} {
+		parallel := GenerateParallel(workers)
+		if !parallel.Equal(serial) || !serial.Equal(parallel) {
+			t.Errorf("GenerateParallel(%d) differs from Generate()", workers)
+		}
+	}
+}
+
+func TestEqualDoesNotModify(t *testing.T) {
+	// a tablebase missing the starting position
+	var root board.Board
+	root.Play(5)
+
+	full, partial := Generate(), GenerateFrom(root)
+	before := len(partial.Positions())
+
+	if full.Equal(partial) {
+		t.Error("tablebases with different positions are equal")
+	}
+
+	if after := len(partial.Positions()); after != before {
+		t.Errorf("Equal added %d positions to it's argument", after-before)
+	}
+}
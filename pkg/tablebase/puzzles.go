@@ -0,0 +1,263 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablebase
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+// PuzzleKind represents the kind of tactical pattern a Puzzle is based on.
+type PuzzleKind int
+
+// Constants representing various kinds of puzzles.
+const (
+	OnlyMove  PuzzleKind = iota // only one move keeps the result
+	MostLose                    // most of the valid moves lose
+	Pitfall                     // a natural looking move loses
+	ForkSetup                   // a winning fork can be created
+)
+
+// puzzleKinds contains all the puzzle kinds, in the order that they are
+// reported in.
+var puzzleKinds = []PuzzleKind{OnlyMove, MostLose, Pitfall, ForkSetup}
+
+// String converts a PuzzleKind into it's string representation.
+func (k PuzzleKind) String() string {
+	switch k {
+	case OnlyMove:
+		return "only-move"
+	case MostLose:
+		return "most-lose"
+	case Pitfall:
+		return "pitfall"
+	case ForkSetup:
+		return "fork"
+	default:
+		return "invalid"
+	}
+}
+
+// ParsePuzzleKind parses the given string into a PuzzleKind. It returns
+// false as the second argument if the string doesn't represent any kind.
+func ParsePuzzleKind(s string) (PuzzleKind, bool) {
+	for _, kind := range puzzleKinds {
+		if kind.String() == s {
+			return kind, true
+		}
+	}
+
+	return 0, false
+}
+
+// Puzzle represents a position where the player to move needs to find a
+// specific move or set of moves to keep the best possible result.
+type Puzzle struct {
+	Position board.Board  // puzzle position
+	Kind     PuzzleKind   // tactical pattern of the puzzle
+	Solution []board.Move // moves which solve the puzzle
+}
+
+// String converts a Puzzle to it's string representation, which consists
+// of the position string, the kind of the puzzle and a comma separated list
+// of the solution moves, separated by spaces.
+func (p Puzzle) String() string {
+	solution := make([]string, len(p.Solution))
+	for i, move := range p.Solution {
+		solution[i] = fmt.Sprint(move)
+	}
+
+	return fmt.Sprintf("%s %s %s", p.Position.PositionString(), p.Kind, strings.Join(solution, ","))
+}
+
+// PuzzleError is the error reported when an invalid puzzle string is
+// provided to ParsePuzzle.
+type PuzzleError struct {
+	puzzle string
+}
+
+func (e PuzzleError) Error() string {
+	return fmt.Sprintf("tablebase: invalid puzzle string %#v", e.puzzle)
+}
+
+// ParsePuzzle parses a puzzle from it's string representation, which is
+// the format produced by Puzzle.String. It returns a PuzzleError if the
+// given string is invalid.
+func ParsePuzzle(s string) (Puzzle, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 {
+		return Puzzle{}, PuzzleError{s}
+	}
+
+	position, err := board.New(fields[0])
+	if err != nil {
+		return Puzzle{}, PuzzleError{s}
+	}
+
+	kind, found := ParsePuzzleKind(fields[1])
+	if !found {
+		return Puzzle{}, PuzzleError{s}
+	}
+
+	solution, found := parseMoveList(position, fields[2])
+	if !found {
+		return Puzzle{}, PuzzleError{s}
+	}
+
+	return Puzzle{
+		Position: position,
+		Kind:     kind,
+		Solution: solution,
+	}, nil
+}
+
+// parseMoveList parses a comma separated list of moves which are valid in
+// the given position. Since row,column moves contain a comma, the moves
+// may only be written in numeric or algebraic notation. It returns false
+// as the second argument if the list is invalid.
+func parseMoveList(position board.Board, list string) ([]board.Move, bool) {
+	var moves []board.Move
+	for _, s := range strings.Split(list, ",") {
+		move, err := board.ParseMove(s)
+		if err != nil || !position.IsValidMove(move) {
+			return nil, false
+		}
+
+		moves = append(moves, move)
+	}
+
+	return moves, true
+}
+
+// ReadPuzzles reads a puzzle set from the given reader, where each non-empty
+// line contains a single puzzle in the format produced by Puzzle.String.
+func ReadPuzzles(r io.Reader) ([]Puzzle, error) {
+	var puzzles []Puzzle
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		puzzle, err := ParsePuzzle(line)
+		if err != nil {
+			return nil, err
+		}
+
+		puzzles = append(puzzles, puzzle)
+	}
+
+	return puzzles, scanner.Err()
+}
+
+// Puzzles scans every position in the tablebase and reports all the
+// positions which form a puzzle for the player to move. A single position
+// may be reported multiple times with different kinds.
+func (t *Table) Puzzles() []Puzzle {
+	var puzzles []Puzzle
+	for _, data := range t.Positions() {
+		puzzles = append(puzzles, data.Puzzles()...)
+	}
+
+	return puzzles
+}
+
+// Puzzles reports all the puzzles that the position represented by the
+// boardData forms for the player to move.
+func (b boardData) Puzzles() []Puzzle {
+	moves := b.Moves()
+
+	// positions with a single valid move and lost positions are not
+	// puzzles, since the player's choice doesn't matter
+	if len(moves) < 2 || moves[0].eval.Outcome() == evaluation.Lost {
+		return nil
+	}
+
+	best := moves[0].eval
+
+	var holding []board.Move // moves which keep the best outcome
+	var losing []board.Move  // moves which lose the game
+	var forks []board.Move   // winning moves which create a fork
+
+	// moves which create a fork for the player to move
+	forking := b.board.ForkingMoves(b.board.Turn())
+
+	for _, entry := range moves {
+		if entry.eval.Outcome() == best.Outcome() {
+			holding = append(holding, entry.move)
+		}
+
+		if entry.eval.Outcome() == evaluation.Lost {
+			losing = append(losing, entry.move)
+		}
+
+		if entry.eval.Outcome() == evaluation.Won {
+			for _, move := range forking {
+				if move == entry.move {
+					forks = append(forks, entry.move)
+				}
+			}
+		}
+	}
+
+	var puzzles []Puzzle
+	add := func(kind PuzzleKind, solution []board.Move) {
+		puzzles = append(puzzles, Puzzle{
+			Position: b.board,
+			Kind:     kind,
+			Solution: solution,
+		})
+	}
+
+	if len(holding) == 1 {
+		add(OnlyMove, holding)
+	}
+
+	if 2*len(losing) > len(moves) {
+		add(MostLose, holding)
+	}
+
+	for _, move := range losing {
+		if isNatural(move) {
+			add(Pitfall, holding)
+			break
+		}
+	}
+
+	// immediate wins are simpler than forks, so forks are only reported
+	// when the player can't win right away
+	if len(forks) > 0 && best != evaluation.Flip(evaluation.LossIn1) {
+		add(ForkSetup, forks)
+	}
+
+	return puzzles
+}
+
+// isNatural checks if the given move is one which looks natural to a
+// player, i.e, the center or one of the corners.
+func isNatural(move board.Move) bool {
+	switch move {
+	case 1, 3, 5, 7, 9:
+		return true
+	default:
+		return false
+	}
+}
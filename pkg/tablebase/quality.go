@@ -0,0 +1,77 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablebase
+
+import (
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+// MoveQuality represents the quality of a valid move in a position.
+type MoveQuality struct {
+	Move    board.Move     // move being rated
+	Eval    evaluation.Rel // evaluation of the move for the player to move
+	Penalty float64        // normalized penalty, see evaluation.Penalty
+}
+
+// Grade classifies the move by it's penalty.
+func (q MoveQuality) Grade() evaluation.Grade {
+	return evaluation.GradePenalty(q.Penalty)
+}
+
+// MoveQualities returns the quality of each valid move in the given
+// position, ordered from the best move to the worst. It returns nil if the
+// position is not legal, or the game has finished.
+func (t *Table) MoveQualities(b board.Board) []MoveQuality {
+	data, found := t.Search(b)
+	if !found {
+		return nil
+	}
+
+	return data.MoveQualities()
+}
+
+// MoveQualities returns the quality of each valid move in the position
+// represented by the boardData, ordered from the best move to the worst.
+func (b boardData) MoveQualities() []MoveQuality {
+	moves := b.Moves()
+	if len(moves) == 0 {
+		return nil
+	}
+
+	best := moves[0].eval
+
+	qualities := make([]MoveQuality, len(moves))
+	for i, entry := range moves {
+		qualities[i] = MoveQuality{
+			Move:    entry.move,
+			Eval:    entry.eval,
+			Penalty: evaluation.Penalty(best, entry.eval),
+		}
+	}
+
+	return qualities
+}
+
+// Penalty returns the normalized penalty of playing the given move in the
+// position represented by the boardData. It returns false as the second
+// argument if the move is not valid.
+func (b boardData) Penalty(move board.Move) (float64, bool) {
+	entry, found := b.moveMap.Search(move)
+	if !found {
+		return 0, false
+	}
+
+	return evaluation.Penalty(b.Moves()[0].eval, entry.eval), true
+}
@@ -0,0 +1,87 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablebase
+
+import (
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+func TestMoveQualities(t *testing.T) {
+	tests := []struct {
+		position string
+		grades   map[board.Move]evaluation.Grade
+	}{
+		// win, win to draw, and win to loss
+		{"xx.oo....", map[board.Move]evaluation.Grade{
+			3: evaluation.Best, 6: evaluation.Mistake, 9: evaluation.Blunder,
+		}},
+		// delayed win
+		{"x.o.x..o.", map[board.Move]evaluation.Grade{
+			9: evaluation.Best, 4: evaluation.Inaccuracy, 2: evaluation.Blunder,
+		}},
+		// draw to loss
+		{"x...o...x", map[board.Move]evaluation.Grade{
+			2: evaluation.Best, 8: evaluation.Best, 3: evaluation.Mistake,
+		}},
+		// faster loss
+		{"xo.x.....", map[board.Move]evaluation.Grade{
+			7: evaluation.Best, 5: evaluation.Inaccuracy,
+		}},
+	}
+
+	table := Generate()
+	for _, test := range tests {
+		position, err := board.New(test.position)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		qualities := table.MoveQualities(position)
+		if len(qualities) == 0 {
+			t.Fatalf("%s: no move qualities", test.position)
+		}
+
+		for _, q := range qualities {
+			want, found := test.grades[q.Move]
+			if found && q.Grade() != want {
+				t.Errorf("%s: move %d is a %s, want %s", test.position, q.Move, q.Grade(), want)
+			}
+		}
+	}
+}
+
+func TestBestMovePenalty(t *testing.T) {
+	for _, data := range Generate().Positions() {
+		qualities := data.MoveQualities()
+		for i, q := range qualities {
+			if i > 0 && q.Penalty < qualities[i-1].Penalty {
+				t.Fatalf("%s: move qualities aren't ordered", data.Position().PositionString())
+			}
+
+			penalty, _ := data.Penalty(q.Move)
+			if penalty != q.Penalty {
+				t.Fatalf("%s: Penalty(%d) = %v, want %v", data.Position().PositionString(), q.Move, penalty, q.Penalty)
+			}
+		}
+
+		for _, move := range data.BestMoves() {
+			if penalty, valid := data.Penalty(move); !valid || penalty != 0 {
+				t.Fatalf("%s: best move %d has penalty %v", data.Position().PositionString(), move, penalty)
+			}
+		}
+	}
+}
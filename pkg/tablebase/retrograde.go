@@ -0,0 +1,85 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablebase
+
+import (
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+// GenerateRetrograde generates the tablebase of all the positions reachable
+// from the given root position using retrograde analysis, as done for chess
+// endgame tablebases. It produces the same tablebase as a recursive search
+// from the root, but without any recursion.
+//
+// All the positions are first enumerated layer by layer, where each layer
+// contains the positions with the same move number. Then, starting from the
+// last layer, the evaluation of each position is propagated backwards to
+// it's predecessors, i.e, the positions in the previous layer from which it
+// can be reached by a single move. A position is evaluated once all of it's
+// successors have been propagated, so the positions of a layer are fully
+// evaluated when the analysis reaches it.
+func GenerateRetrograde(root board.Board) *Table {
+	layers := generateLayers(root, 1)
+
+	// best evaluations found so far for each position, relative to the
+	// player to move; the default is the lowest possible evaluation
+	evals := make([][]evaluation.Rel, len(layers))
+	for move, layer := range layers {
+		evals[move] = make([]evaluation.Rel, len(layer.boards))
+		for i := range evals[move] {
+			evals[move][i] = evaluation.LossIn1
+		}
+	}
+
+	for move := len(layers) - 1; move >= 0; move-- {
+		for i, b := range layers[move].boards {
+			// terminal positions have hardcoded evaluations
+			switch b.State() {
+			case board.PlayerXWon, board.PlayerOWon:
+				evals[move][i] = evaluation.LossIn1
+			case board.GameDrawn:
+				evals[move][i] = evaluation.Draw
+			}
+
+			if move == 0 {
+				continue
+			}
+
+			// propagate the evaluation to the predecessors, flipped to the
+			// perspective of the player making the move
+			eval := evaluation.Flip(evals[move][i])
+			previous := layers[move-1]
+
+			for cell := board.Move(1); cell <= 9; cell++ {
+				predecessor := b
+				if predecessor.Undo(cell) != nil || predecessor.State() != board.Unfinished {
+					continue
+				}
+
+				// the predecessor may not be reachable from the root
+				if index, found := previous.index[predecessor]; found && eval.Compare(evals[move-1][index]) > 0 {
+					evals[move-1][index] = eval
+				}
+			}
+		}
+	}
+
+	var table Table
+	table.storeLayers(layers, 1, func(b board.Board, i int, _ moveMap) evaluation.Rel {
+		return evals[b.MoveNumber()][i]
+	})
+
+	return &table
+}
@@ -0,0 +1,38 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablebase
+
+import (
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+)
+
+func TestGenerateRetrograde(t *testing.T) {
+	serial, retrograde := Generate(), GenerateRetrograde(board.Board{})
+	if !retrograde.Equal(serial) || !serial.Equal(retrograde) {
+		t.Fatal("GenerateRetrograde differs from Generate")
+	}
+
+	for _, data := range serial.Positions() {
+		index, found := retrograde.indexOf(data.Position())
+		if !found {
+			t.Fatalf("%s: missing from retrograde tablebase", data.Position().PositionString())
+		}
+
+		if eval := index.fetch().AbsEval(); eval != data.AbsEval() {
+			t.Errorf("%s: retrograde eval %s, want %s", data.Position().PositionString(), eval, data.AbsEval())
+		}
+	}
+}
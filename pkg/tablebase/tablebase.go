@@ -16,36 +16,120 @@ package tablebase
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"laptudirm.com/x/wreck/pkg/board"
 	"laptudirm.com/x/wreck/pkg/evaluation"
 )
 
-// tablebase is table of all possible tic tac toe board positions and their
+// Table is a table of all possible tic tac toe board positions and their
 // evaluations, i.e, how good they are for each player.
-type tablebase struct {
+type Table struct {
 	data [10][]boardData
 }
 
-func (t *tablebase) Search(b board.Board) (boardData, bool) {
+// Search looks for the given position in the tablebase. It returns false
+// as the second argument if the position is not present. Search doesn't
+// modify the tablebase, so it is safe for concurrent use.
+func (t *Table) Search(b board.Board) (boardData, bool) {
 	index, found := t.indexOf(b)
-	if found {
+	if !found {
+		return boardData{}, false
+	}
+
+	return index.fetch(), true
+}
+
+// SearchOrGenerate is like Search, but if the position is legal but not
+// present in the tablebase, like a custom setup which can't be reached from
+// the tablebase's root, the positions reachable from it are generated and
+// added to the tablebase. Therefore, it is not safe for concurrent use. It
+// returns false as the second argument if the position is not legal.
+func (t *Table) SearchOrGenerate(b board.Board) (boardData, bool) {
+	if data, found := t.Search(b); found {
+		return data, true
+	}
+
+	// lazily generate the missing position
+	if b.IsLegal() {
+		index, _ := t.generateBoardsFrom(b)
 		return index.fetch(), true
 	}
 
 	return boardData{}, false
 }
 
+// Eval returns the absolute evaluation of the given position. It returns
+// false as the second argument if the position is not present.
+func (t *Table) Eval(b board.Board) (evaluation.Abs, bool) {
+	data, found := t.Search(b)
+	return data.eval, found
+}
+
+// BestMoves returns the moves in the given position which have the best
+// possible evaluation for the player to move.
+func (t *Table) BestMoves(b board.Board) []board.Move {
+	data, found := t.Search(b)
+	if !found {
+		return nil
+	}
+
+	return data.BestMoves()
+}
+
+// Positions returns all the positions present in the tablebase, ordered by
+// their move number.
+func (t *Table) Positions() []boardData {
+	var positions []boardData
+	for _, data := range t.data {
+		positions = append(positions, data...)
+	}
+
+	return positions
+}
+
+// Equal checks if the given tablebases contain the same positions, with
+// the same evaluations and moves. The order in which the positions are
+// stored in the tablebases doesn't matter. Neither tablebase is modified.
+func (t *Table) Equal(u *Table) bool {
+	for move := range t.data {
+		if len(t.data[move]) != len(u.data[move]) {
+			return false
+		}
+
+		for _, data := range t.data[move] {
+			index, found := u.indexOf(data.board)
+			if !found {
+				return false
+			}
+
+			other := index.fetch()
+			if data.eval != other.eval || len(data.Moves()) != len(other.Moves()) {
+				return false
+			}
+
+			for _, entry := range data.Moves() {
+				otherEntry, found := other.Search(entry.move)
+				if !found || entry.eval != otherEntry.eval || entry.Data().board != otherEntry.Data().board {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
 // get fetches the BoardData present at the given boardIndex in the
 // tablebase.
-func (t *tablebase) get(index boardIndex) boardData {
+func (t *Table) get(index boardIndex) boardData {
 	return t.data[index.move][index.index]
 }
 
 // indexOf fetches the boardIndex of a tic tac toe position from the
 // tablebase. It returns false as the second argument if the position can't
 // be found.
-func (t *tablebase) indexOf(b board.Board) (boardIndex, bool) {
+func (t *Table) indexOf(b board.Board) (boardIndex, bool) {
 	move := b.MoveNumber()
 	for i, data := range t.data[move] {
 		if data.board == b {
@@ -62,7 +146,7 @@ func (t *tablebase) indexOf(b board.Board) (boardIndex, bool) {
 }
 
 // pushBoard adds a BoardData entry to the tablebase.
-func (t *tablebase) pushBoard(b boardData) boardIndex {
+func (t *Table) pushBoard(b boardData) boardIndex {
 	move := b.board.MoveNumber()
 
 	// add to tablebase
@@ -79,7 +163,7 @@ type boardIndex struct {
 	move  int // move number
 	index int // tablebase index
 
-	table *tablebase // parent tablebase
+	table *Table // parent tablebase
 }
 
 // fetch gets the boardData at the current index in the parent tablebase,
@@ -96,11 +180,18 @@ type boardData struct {
 	eval    evaluation.Abs // position evaluation from children
 	moveMap                // moves mapped to resulting positions
 
-	table *tablebase // parent tablebase
+	table *Table // parent tablebase
 }
 
-// String converts a BoardData instance to it's string representation.
+// String converts a BoardData instance to it's string representation, with
+// the moves in numeric notation.
 func (b boardData) String() string {
+	return b.Format(board.Numeric)
+}
+
+// Format converts a BoardData instance to it's string representation, with
+// the moves in the given notation.
+func (b boardData) Format(notation board.Notation) string {
 	s := fmt.Sprintf("%s\n", b.board)
 	switch b.board.State() {
 	case board.Unfinished:
@@ -115,13 +206,16 @@ func (b boardData) String() string {
 		moves := b.Moves()
 		for _, data := range moves {
 			nextEval := data.index.fetch().eval
-			s += fmt.Sprintf("  Move %d : %s\n", data.move, nextEval)
+			s += fmt.Sprintf("  Move %s : %s\n", notation.FormatMove(data.move), nextEval)
 		}
 
-	case board.PlayerXWon:
-		s += "\n(player x won)\n"
-	case board.PlayerOWon:
-		s += "\n(player o won)\n"
+	case board.PlayerXWon, board.PlayerOWon:
+		var lines []string
+		for _, line := range b.board.WinningLines() {
+			lines = append(lines, notation.FormatLine(line))
+		}
+
+		s += fmt.Sprintf("\n(%s on %s)\n", b.board.State(), strings.Join(lines, " and "))
 	case board.GameDrawn:
 		s += "\n(game drawn)\n"
 	}
@@ -156,6 +250,35 @@ func (b boardData) RelEval() evaluation.Rel {
 	return evaluation.ToRel(b.eval, b.board)
 }
 
+// BestMoves returns the moves in the position which have the best possible
+// evaluation for the player to move.
+func (b boardData) BestMoves() []board.Move {
+	var best []board.Move
+	for _, entry := range b.Moves() {
+		if entry.eval != b.Moves()[0].eval {
+			break
+		}
+
+		best = append(best, entry.move)
+	}
+
+	return best
+}
+
+// Line returns the principal variation of the position, i.e, the sequence
+// of moves which are played from the position if both players play
+// perfectly till the game ends.
+func (b boardData) Line() []board.Move {
+	var line []board.Move
+	for data := b; len(data.Moves()) > 0; {
+		best := data.Moves()[0]
+		line = append(line, best.move)
+		data = best.index.fetch()
+	}
+
+	return line
+}
+
 // moveMap maps all valid moves in a position to their corresponding
 // boardData.
 type moveMap struct {
@@ -191,7 +314,7 @@ func (m *moveMap) add(move board.Move, index boardIndex) {
 // initiates sorting of the entries according to their evaluation.
 func (m *moveMap) finalize() {
 	sort.Slice(m.boardMap, func(i, j int) bool {
-		return m.boardMap[i].eval > m.boardMap[j].eval
+		return m.boardMap[i].eval.Compare(m.boardMap[j].eval) > 0
 	})
 }
 
@@ -201,3 +324,19 @@ type moveMapEntry struct {
 	index boardIndex     // board state after move
 	eval  evaluation.Rel // move evaluation
 }
+
+// Move returns the move represented by the moveMapEntry.
+func (e moveMapEntry) Move() board.Move {
+	return e.move
+}
+
+// Eval returns the evaluation of the moveMapEntry's move, relative to the
+// player making the move.
+func (e moveMapEntry) Eval() evaluation.Rel {
+	return e.eval
+}
+
+// Data returns the boardData representing the position after the move.
+func (e moveMapEntry) Data() boardData {
+	return e.index.fetch()
+}
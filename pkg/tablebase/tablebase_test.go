@@ -0,0 +1,54 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablebase
+
+import (
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+)
+
+func TestSearchOrGenerate(t *testing.T) {
+	table := Generate()
+	positions := len(table.Positions())
+
+	// a setup with x to move again, which can't be reached from the start
+	setup, err := board.NewSetup("....x....", board.PlayerX)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, found := table.Search(setup); found {
+		t.Fatal("Search found a position missing from the tablebase")
+	}
+
+	if len(table.Positions()) != positions {
+		t.Fatal("Search modified the tablebase")
+	}
+
+	data, found := table.SearchOrGenerate(setup)
+	if !found || data.Position() != setup {
+		t.Fatal("SearchOrGenerate didn't generate a legal position")
+	}
+
+	if _, found := table.Search(setup); !found {
+		t.Error("generated position missing from the tablebase")
+	}
+
+	// x has won twice, which isn't legal
+	illegal, _ := board.NewSetup("xxxxxx...", board.PlayerO)
+	if _, found := table.SearchOrGenerate(illegal); found {
+		t.Error("SearchOrGenerate generated an illegal position")
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transposition implements a transposition table, which caches the
+// results of searching positions so that they don't need to be searched
+// again when they are reached through a different order of moves. It is
+// meant to be shared by the search engines, and is safe for concurrent use
+// by a parallel search.
+package transposition
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+// Bound represents the relation of a stored score to the actual score of
+// the position, which depends on how the search of the position ended.
+type Bound uint8
+
+// Constants representing various bounds.
+const (
+	Exact Bound = iota // score is the actual score
+	Lower              // actual score is at least the score, fail high
+	Upper              // actual score is at most the score, fail low
+)
+
+// String converts a Bound into it's string representation.
+func (b Bound) String() string {
+	switch b {
+	case Exact:
+		return "exact"
+	case Lower:
+		return "lower"
+	case Upper:
+		return "upper"
+	default:
+		return "invalid bound"
+	}
+}
+
+// Policy represents the strategy used to choose which entry is replaced
+// when a bucket of the Table is full.
+type Policy int
+
+// Constants representing various replacement policies.
+const (
+	// AlwaysReplace always stores the new entry, replacing the entry
+	// from the oldest search, breaking ties by replacing the shallowest.
+	AlwaysReplace Policy = iota
+
+	// DepthPreferred only replaces an entry from the current search if
+	// the new entry was searched at least as deep.
+	DepthPreferred
+)
+
+// Entry represents the result of searching a position.
+type Entry struct {
+	Hash  uint64         // hash of the position
+	Depth int            // depth the position was searched to
+	Bound Bound          // bound type of the score
+	Score evaluation.Rel // score relative to the player to move
+	Move  board.Move     // best move found in the position
+}
+
+// slot is a single place for an entry in a bucket.
+type slot struct {
+	Entry
+	used bool  // whether the slot contains an entry
+	age  uint8 // search the entry was stored in
+}
+
+// BucketSize is the number of entries stored in each bucket of a Table.
+const BucketSize = 4
+
+// bucket is a group of slots for positions with the same table index.
+type bucket [BucketSize]slot
+
+// Table is a fixed size transposition table, where entries are stored in
+// buckets selected by the hash of their positions.
+type Table struct {
+	// statistics, kept first for 64-bit alignment of atomic operations
+	probes  int64
+	hits    int64
+	stores  int64
+	evicted int64
+
+	buckets []bucket
+	mask    uint64 // mask to convert a hash into a bucket index
+
+	locks  [256]sync.Mutex // striped locks protecting the buckets
+	policy Policy
+	age    uint8 // current search
+}
+
+// New creates a new Table which can store at least the given number of
+// entries, using the given replacement Policy. The number of buckets is
+// rounded up to a power of two.
+func New(entries int, policy Policy) *Table {
+	buckets := 1
+	for buckets*BucketSize < entries {
+		buckets *= 2
+	}
+
+	return &Table{
+		buckets: make([]bucket, buckets),
+		mask:    uint64(buckets - 1),
+		policy:  policy,
+	}
+}
+
+// Size returns the number of entries the Table can store.
+func (t *Table) Size() int {
+	return len(t.buckets) * BucketSize
+}
+
+// NewSearch signals the start of a new search, which makes the entries of
+// the previous searches preferred for replacement.
+func (t *Table) NewSearch() {
+	t.lockAll()
+	defer t.unlockAll()
+
+	t.age++
+}
+
+// Clear removes all the entries from the Table and resets it's statistics.
+func (t *Table) Clear() {
+	t.lockAll()
+	defer t.unlockAll()
+
+	for i := range t.buckets {
+		t.buckets[i] = bucket{}
+	}
+
+	atomic.StoreInt64(&t.probes, 0)
+	atomic.StoreInt64(&t.hits, 0)
+	atomic.StoreInt64(&t.stores, 0)
+	atomic.StoreInt64(&t.evicted, 0)
+}
+
+// Probe looks for the entry of the position with the given hash, which is
+// at the given ply from the root of the search. The score of the entry is
+// converted to be relative to the root, see Store. It returns false as the
+// second argument if the position is not in the Table.
+func (t *Table) Probe(hash uint64, ply int) (Entry, bool) {
+	atomic.AddInt64(&t.probes, 1)
+
+	index := hash & t.mask
+	lock := &t.locks[index%uint64(len(t.locks))]
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	for _, slot := range t.buckets[index] {
+		if slot.used && slot.Hash == hash {
+			atomic.AddInt64(&t.hits, 1)
+
+			entry := slot.Entry
+			entry.Score = fromNode(entry.Score, ply)
+			return entry, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+// Store stores the given entry for the position with the given hash, which
+// is at the given ply from the root of the search.
+//
+// Winning and losing scores found by a search are relative to the root,
+// i.e, the steps till the game is decided are counted from the root. Since
+// the position may be reached at a different ply later, these scores are
+// converted to be relative to the position before storing them, and
+// converted back to the ply at which the position is probed by Probe.
+func (t *Table) Store(hash uint64, depth, ply int, bound Bound, score evaluation.Rel, move board.Move) {
+	entry := Entry{
+		Hash:  hash,
+		Depth: depth,
+		Bound: bound,
+		Score: toNode(score, ply),
+		Move:  move,
+	}
+
+	index := hash & t.mask
+	lock := &t.locks[index%uint64(len(t.locks))]
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	b := &t.buckets[index]
+
+	// find the slot to store the entry in, preferring the position's old
+	// entry over empty slots
+	victim := -1
+	for i, slot := range b {
+		if slot.used && slot.Hash == hash {
+			victim = i
+			break
+		}
+
+		if !slot.used && victim == -1 {
+			victim = i
+		}
+	}
+
+	if victim == -1 {
+		victim = t.chooseVictim(b, depth)
+		if victim == -1 {
+			// policy prefers the existing entries
+			return
+		}
+
+		atomic.AddInt64(&t.evicted, 1)
+	}
+
+	atomic.AddInt64(&t.stores, 1)
+	b[victim] = slot{Entry: entry, used: true, age: t.age}
+}
+
+// chooseVictim chooses an entry in the given full bucket to replace with
+// an entry of the given depth, according to the Table's Policy. It returns
+// -1 if no entry should be replaced.
+func (t *Table) chooseVictim(b *bucket, depth int) int {
+	// find the entry from the oldest search, and then the shallowest
+	victim := 0
+	for i := 1; i < len(b); i++ {
+		oldAge, newAge := t.age-b[victim].age, t.age-b[i].age
+		if newAge > oldAge || (newAge == oldAge && b[i].Depth < b[victim].Depth) {
+			victim = i
+		}
+	}
+
+	if t.policy == DepthPreferred && b[victim].age == t.age && b[victim].Depth > depth {
+		return -1
+	}
+
+	return victim
+}
+
+// Stats represents the usage statistics of a Table.
+type Stats struct {
+	Probes  int64 // number of probes
+	Hits    int64 // number of successful probes
+	Stores  int64 // number of stored entries
+	Evicted int64 // number of entries replaced by other positions
+}
+
+// HitRate returns the fraction of probes which were successful.
+func (s Stats) HitRate() float64 {
+	if s.Probes == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(s.Probes)
+}
+
+// Stats returns the usage statistics of the Table.
+func (t *Table) Stats() Stats {
+	return Stats{
+		Probes:  atomic.LoadInt64(&t.probes),
+		Hits:    atomic.LoadInt64(&t.hits),
+		Stores:  atomic.LoadInt64(&t.stores),
+		Evicted: atomic.LoadInt64(&t.evicted),
+	}
+}
+
+// lockAll locks all the buckets of the Table.
+func (t *Table) lockAll() {
+	for i := range t.locks {
+		t.locks[i].Lock()
+	}
+}
+
+// unlockAll unlocks all the buckets of the Table.
+func (t *Table) unlockAll() {
+	for i := range t.locks {
+		t.locks[i].Unlock()
+	}
+}
+
+// toNode converts a score relative to the root of the search into a score
+// relative to the position at the given ply.
+func toNode(score evaluation.Rel, ply int) evaluation.Rel {
+	switch score.Outcome() {
+	case evaluation.Won:
+		// win is ply steps closer from the position
+		return evaluation.WinIn(score.Steps() - ply)
+	case evaluation.Lost:
+		return evaluation.LossIn(score.Steps() - ply)
+	default:
+		return score
+	}
+}
+
+// fromNode converts a score relative to the position at the given ply into
+// a score relative to the root of the search.
+func fromNode(score evaluation.Rel, ply int) evaluation.Rel {
+	return toNode(score, -ply)
+}
@@ -0,0 +1,207 @@
+// Copyright © 2022 Rak Laptudirm <rak@laptudirm.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transposition
+
+import (
+	"sync"
+	"testing"
+
+	"laptudirm.com/x/wreck/pkg/board"
+	"laptudirm.com/x/wreck/pkg/evaluation"
+)
+
+func TestProbe(t *testing.T) {
+	table := New(1024, AlwaysReplace)
+	table.Store(42, 3, 0, Lower, evaluation.Draw, 5)
+
+	entry, found := table.Probe(42, 0)
+	if !found {
+		t.Fatal("stored entry not found")
+	}
+
+	want := Entry{Hash: 42, Depth: 3, Bound: Lower, Score: evaluation.Draw, Move: 5}
+	if entry != want {
+		t.Errorf("Probe(42) = %+v, want %+v", entry, want)
+	}
+
+	if _, found := table.Probe(43, 0); found {
+		t.Error("Probe(43) found an entry which wasn't stored")
+	}
+
+	// storing the same position again replaces it's entry
+	table.Store(42, 4, 0, Exact, evaluation.WinIn(1), 9)
+	if entry, _ := table.Probe(42, 0); entry.Depth != 4 || entry.Move != 9 {
+		t.Errorf("Probe(42) after replacing = %+v", entry)
+	}
+
+	table.Clear()
+	if _, found := table.Probe(42, 0); found {
+		t.Error("Probe(42) found an entry after Clear")
+	}
+}
+
+func TestProbePly(t *testing.T) {
+	tests := []struct {
+		score     evaluation.Rel
+		store     int // ply the entry is stored at
+		probe     int // ply the entry is probed at
+		want      evaluation.Rel
+		wantStore evaluation.Rel // score stored in the table
+	}{
+		{evaluation.WinIn(5), 2, 2, evaluation.WinIn(5), evaluation.WinIn(3)},
+		{evaluation.WinIn(5), 2, 4, evaluation.WinIn(7), evaluation.WinIn(3)},
+		{evaluation.LossIn(6), 3, 1, evaluation.LossIn(4), evaluation.LossIn(3)},
+		{evaluation.Draw, 3, 1, evaluation.Draw, evaluation.Draw},
+		{evaluation.Estimate(120), 2, 5, evaluation.Estimate(120), evaluation.Estimate(120)},
+	}
+
+	for _, test := range tests {
+		if stored := toNode(test.score, test.store); stored != test.wantStore {
+			t.Errorf("toNode(%s, %d) = %s, want %s", test.score, test.store, stored, test.wantStore)
+		}
+
+		table := New(16, AlwaysReplace)
+		table.Store(1, 1, test.store, Exact, test.score, 1)
+
+		entry, _ := table.Probe(1, test.probe)
+		if entry.Score != test.want {
+			t.Errorf("%s stored at ply %d, probed at ply %d = %s, want %s",
+				test.score, test.store, test.probe, entry.Score, test.want)
+		}
+	}
+}
+
+// fill fills the single bucket of the given table with entries of the given
+// depths, whose hashes are their indices plus one.
+func fill(table *Table, depths ...int) {
+	for i, depth := range depths {
+		table.Store(uint64(i+1), depth, 0, Exact, evaluation.Draw, 1)
+	}
+}
+
+func TestAlwaysReplace(t *testing.T) {
+	table := New(BucketSize, AlwaysReplace)
+	fill(table, 5, 1, 3, 4)
+
+	// the shallowest entry is replaced, even by a shallower one
+	table.Store(10, 0, 0, Exact, evaluation.Draw, 1)
+	if _, found := table.Probe(2, 0); found {
+		t.Error("shallowest entry wasn't replaced")
+	}
+
+	if _, found := table.Probe(10, 0); !found {
+		t.Error("new entry wasn't stored")
+	}
+
+	// entries from older searches are replaced first
+	table.NewSearch()
+	table.Store(3, 3, 0, Exact, evaluation.Draw, 1)
+	table.Store(11, 9, 0, Exact, evaluation.Draw, 1)
+	if _, found := table.Probe(10, 0); found {
+		t.Error("shallowest entry from the old search wasn't replaced")
+	}
+
+	if _, found := table.Probe(3, 0); !found {
+		t.Error("entry from the current search was replaced")
+	}
+}
+
+func TestDepthPreferred(t *testing.T) {
+	table := New(BucketSize, DepthPreferred)
+	fill(table, 5, 1, 3, 4)
+
+	// shallower entries don't replace entries from the current search
+	table.Store(10, 0, 0, Exact, evaluation.Draw, 1)
+	if _, found := table.Probe(10, 0); found {
+		t.Error("shallower entry was stored")
+	}
+
+	table.Store(11, 2, 0, Exact, evaluation.Draw, 1)
+	if _, found := table.Probe(11, 0); !found {
+		t.Error("deeper entry wasn't stored")
+	}
+
+	if _, found := table.Probe(2, 0); found {
+		t.Error("shallowest entry wasn't replaced")
+	}
+
+	// entries from older searches are always replaced
+	table.NewSearch()
+	table.Store(12, 0, 0, Exact, evaluation.Draw, 1)
+	if _, found := table.Probe(12, 0); !found {
+		t.Error("entry from the old search wasn't replaced")
+	}
+}
+
+func TestStats(t *testing.T) {
+	table := New(BucketSize, AlwaysReplace)
+	fill(table, 1, 2, 3, 4)
+	table.Store(5, 5, 0, Exact, evaluation.Draw, 1) // evicts 1
+
+	table.Probe(5, 0)
+	table.Probe(4, 0)
+	table.Probe(1, 0)
+	table.Probe(6, 0)
+
+	want := Stats{Probes: 4, Hits: 2, Stores: 5, Evicted: 1}
+	if stats := table.Stats(); stats != want {
+		t.Errorf("Stats() = %+v, want %+v", stats, want)
+	}
+
+	if rate := table.Stats().HitRate(); rate != 0.5 {
+		t.Errorf("HitRate() = %v, want 0.5", rate)
+	}
+
+	table.Clear()
+	if stats := table.Stats(); stats != (Stats{}) {
+		t.Errorf("Stats() after Clear = %+v", stats)
+	}
+
+	if rate := table.Stats().HitRate(); rate != 0 {
+		t.Errorf("HitRate() without probes = %v, want 0", rate)
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	table := New(256, DepthPreferred)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+
+			for i := 0; i < 2000; i++ {
+				hash := uint64(i*7 + worker)
+				move := board.Move(hash%9 + 1)
+
+				table.Store(hash, i%10, 0, Exact, evaluation.Draw, move)
+				if entry, found := table.Probe(hash, 0); found && (entry.Hash != hash || entry.Move != move) {
+					t.Errorf("Probe(%d) = %+v", hash, entry)
+				}
+
+				if worker == 0 && i%500 == 0 {
+					table.NewSearch()
+				}
+			}
+		}(worker)
+	}
+
+	wg.Wait()
+
+	stats := table.Stats()
+	if stats.Probes != 8*2000 || stats.Hits > stats.Probes {
+		t.Errorf("Stats() = %+v after concurrent use", stats)
+	}
+}